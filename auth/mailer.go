@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends a single plain-text email. TwoFactor is its only caller,
+// for both the login second factor and password-reset codes.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// SMTPMailer sends mail through a standard SMTP relay, the default Mailer
+// for production use.
+type SMTPMailer struct {
+	Addr     string // host:port of the SMTP relay
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(addr, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{Addr: addr, Username: username, Password: password, From: from}
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	host := m.Addr
+	if i := strings.IndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+
+	msg := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s\r\n", to, subject, body)
+	return smtp.SendMail(m.Addr, smtp.PlainAuth("", m.Username, m.Password, host), m.From, []string{to}, []byte(msg))
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Redis is a Store backed by a Redis instance, for deployments running
+// more than one API process against the same session cache.
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis opens a Redis client against addr (host:port), authenticating
+// with password if set.
+func NewRedis(addr, password string, db int) *Redis {
+	return &Redis{client: redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})}
+}
+
+func (r *Redis) Get(key string) (string, bool, error) {
+	val, err := r.client.Get(context.Background(), key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+func (r *Redis) Set(key, value string, ttl time.Duration) error {
+	return r.client.Set(context.Background(), key, value, ttl).Err()
+}
+
+func (r *Redis) Incr(key string, ttl time.Duration) (int64, error) {
+	ctx := context.Background()
+	n, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if err := r.client.Expire(ctx, key, ttl).Err(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (r *Redis) Delete(key string) error {
+	return r.client.Del(context.Background(), key).Err()
+}
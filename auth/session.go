@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Session is the cached record behind an issued sid: who it belongs to,
+// where the login came from, and when it stops being valid.
+type Session struct {
+	UserID     uint64    `json:"user_id"`
+	RemoteAddr string    `json:"remote_addr"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// SessionCache lets handlers validate a bearer token's sid against Store
+// without a DB roundtrip, and revoke it independently of the JWT's own
+// expiry (e.g. on logout or a forced sign-out).
+type SessionCache struct {
+	store Store
+	ttl   time.Duration
+}
+
+func NewSessionCache(store Store, ttl time.Duration) *SessionCache {
+	return &SessionCache{store: store, ttl: ttl}
+}
+
+// Put caches a new session for userID and returns the sid to embed in the
+// JWT issued alongside it.
+func (c *SessionCache) Put(userID uint64, remoteAddr string) (string, error) {
+	sid, err := randomID()
+	if err != nil {
+		return "", fmt.Errorf("auth: generating session id: %w", err)
+	}
+
+	value, err := json.Marshal(Session{
+		UserID:     userID,
+		RemoteAddr: remoteAddr,
+		ExpiresAt:  time.Now().Add(c.ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := c.store.Set(sessionKey(sid), string(value), c.ttl); err != nil {
+		return "", err
+	}
+	return sid, nil
+}
+
+// Get returns the cached session for sid, or ok=false if it is missing,
+// expired, or was revoked.
+func (c *SessionCache) Get(sid string) (Session, bool, error) {
+	raw, ok, err := c.store.Get(sessionKey(sid))
+	if err != nil || !ok {
+		return Session{}, false, err
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(raw), &session); err != nil {
+		return Session{}, false, err
+	}
+	return session, true, nil
+}
+
+// Revoke invalidates sid immediately, e.g. on logout.
+func (c *SessionCache) Revoke(sid string) error {
+	return c.store.Delete(sessionKey(sid))
+}
+
+func sessionKey(sid string) string { return "session:" + sid }
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
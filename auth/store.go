@@ -0,0 +1,80 @@
+// Package auth provides login session caching and failed-login throttling
+// on top of a pluggable key/value Store (in-memory for a single process,
+// Redis for a deployment running more than one), plus an optional
+// email-code second factor for login.
+package auth
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store is the key/value backend SessionCache and LoginThrottle are built
+// on top of. Memory and Redis are the two implementations; both treat a
+// missing or expired key the same way (ok=false).
+type Store interface {
+	Get(key string) (value string, ok bool, err error)
+	Set(key, value string, ttl time.Duration) error
+	// Incr increments the integer stored at key (starting from 0), resets
+	// its ttl to ttl on every call, and returns the new value.
+	Incr(key string, ttl time.Duration) (int64, error)
+	Delete(key string) error
+}
+
+type memoryItem struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Memory is a Store backed by a map, suitable for local development or a
+// single-instance deployment. It is not shared across processes.
+type Memory struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+func NewMemory() *Memory {
+	return &Memory{items: make(map[string]memoryItem)}
+}
+
+func (m *Memory) Get(key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok || time.Now().After(item.expiresAt) {
+		delete(m.items, key)
+		return "", false, nil
+	}
+	return item.value, true, nil
+}
+
+func (m *Memory) Set(key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items[key] = memoryItem{value: value, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *Memory) Incr(key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	if item, ok := m.items[key]; ok && time.Now().Before(item.expiresAt) {
+		n, _ = strconv.ParseInt(item.value, 10, 64)
+	}
+	n++
+	m.items[key] = memoryItem{value: strconv.FormatInt(n, 10), expiresAt: time.Now().Add(ttl)}
+	return n, nil
+}
+
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.items, key)
+	return nil
+}
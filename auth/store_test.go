@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryGetSet(t *testing.T) {
+	m := NewMemory()
+
+	if _, ok, _ := m.Get("missing"); ok {
+		t.Fatal("expected miss for an unset key")
+	}
+
+	if err := m.Set("k", "v", time.Hour); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	value, ok, err := m.Get("k")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if !ok || value != "v" {
+		t.Fatalf("Get() = %q, %v, want \"v\", true", value, ok)
+	}
+}
+
+func TestMemoryExpires(t *testing.T) {
+	m := NewMemory()
+	if err := m.Set("k", "v", -time.Second); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if _, ok, _ := m.Get("k"); ok {
+		t.Fatal("expected an already-expired key to miss")
+	}
+}
+
+func TestMemoryIncr(t *testing.T) {
+	m := NewMemory()
+
+	for i, want := range []int64{1, 2, 3} {
+		got, err := m.Incr("attempts", time.Hour)
+		if err != nil {
+			t.Fatalf("Incr() #%d returned error: %v", i, err)
+		}
+		if got != want {
+			t.Fatalf("Incr() #%d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestMemoryDelete(t *testing.T) {
+	m := NewMemory()
+	_ = m.Set("k", "v", time.Hour)
+	if err := m.Delete("k"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, ok, _ := m.Get("k"); ok {
+		t.Fatal("expected key to be gone after Delete")
+	}
+}
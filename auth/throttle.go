@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+)
+
+// loginCountWindow is how long a failed-attempt counter survives without
+// a further failure before it resets.
+const loginCountWindow = 15 * time.Minute
+
+// maxBackoffExponent caps how many times baseDelay is doubled, so a
+// pathological number of failures can't overflow the shift.
+const maxBackoffExponent = 10
+
+// LoginThrottle tracks failed login attempts per email and per source IP
+// in Store, locking out further attempts with exponential backoff once
+// MaxAttempts is exceeded.
+type LoginThrottle struct {
+	store       Store
+	maxAttempts int64
+	baseDelay   time.Duration
+}
+
+func NewLoginThrottle(store Store, maxAttempts int, baseDelay time.Duration) *LoginThrottle {
+	return &LoginThrottle{store: store, maxAttempts: int64(maxAttempts), baseDelay: baseDelay}
+}
+
+// Allow reports whether a login attempt for email from remoteAddr may
+// proceed, without recording anything. If not, retryAfter is how long the
+// caller should wait before trying again.
+func (t *LoginThrottle) Allow(email, remoteAddr string) (allowed bool, retryAfter time.Duration, err error) {
+	for _, key := range []string{attemptKey("email", email), attemptKey("ip", remoteAddr)} {
+		raw, ok, err := t.store.Get(key)
+		if err != nil {
+			return false, 0, err
+		}
+		if !ok {
+			continue
+		}
+
+		failures, _ := strconv.ParseInt(raw, 10, 64)
+		if failures >= t.maxAttempts {
+			if d := t.lockDuration(failures); d > retryAfter {
+				retryAfter = d
+			}
+		}
+	}
+	return retryAfter == 0, retryAfter, nil
+}
+
+// RecordFailure records a failed login attempt for email and remoteAddr,
+// reporting whether the account is now locked and for how long.
+func (t *LoginThrottle) RecordFailure(email, remoteAddr string) (locked bool, retryAfter time.Duration, err error) {
+	var peak int64
+	for _, key := range []string{attemptKey("email", email), attemptKey("ip", remoteAddr)} {
+		n, err := t.store.Incr(key, loginCountWindow)
+		if err != nil {
+			return false, 0, err
+		}
+		if n > peak {
+			peak = n
+		}
+	}
+	if peak < t.maxAttempts {
+		return false, 0, nil
+	}
+	return true, t.lockDuration(peak), nil
+}
+
+// Reset clears the failure counters for email and remoteAddr, called on a
+// successful login.
+func (t *LoginThrottle) Reset(email, remoteAddr string) error {
+	if err := t.store.Delete(attemptKey("email", email)); err != nil {
+		return err
+	}
+	return t.store.Delete(attemptKey("ip", remoteAddr))
+}
+
+// lockDuration grows exponentially with every failure past maxAttempts.
+func (t *LoginThrottle) lockDuration(failures int64) time.Duration {
+	over := failures - t.maxAttempts
+	if over < 0 {
+		over = 0
+	}
+	if over > maxBackoffExponent {
+		over = maxBackoffExponent
+	}
+	return t.baseDelay << uint(over)
+}
+
+func attemptKey(kind, id string) string { return "loginattempts:" + kind + ":" + id }
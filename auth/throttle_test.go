@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginThrottleLocksAfterMaxAttempts(t *testing.T) {
+	throttle := NewLoginThrottle(NewMemory(), 3, time.Second)
+
+	for i := 0; i < 2; i++ {
+		locked, _, err := throttle.RecordFailure("jake@jake.jake", "1.2.3.4")
+		if err != nil {
+			t.Fatalf("RecordFailure returned error: %v", err)
+		}
+		if locked {
+			t.Fatalf("attempt %d: locked too early", i+1)
+		}
+	}
+
+	locked, retryAfter, err := throttle.RecordFailure("jake@jake.jake", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("RecordFailure returned error: %v", err)
+	}
+	if !locked {
+		t.Fatal("expected the account to be locked after 3 failures")
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("retryAfter = %v, want > 0", retryAfter)
+	}
+
+	allowed, _, err := throttle.Allow("jake@jake.jake", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+	if allowed {
+		t.Fatal("expected Allow to reject a locked account")
+	}
+}
+
+func TestLoginThrottleResetClearsLock(t *testing.T) {
+	throttle := NewLoginThrottle(NewMemory(), 3, time.Second)
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := throttle.RecordFailure("jake@jake.jake", "1.2.3.4"); err != nil {
+			t.Fatalf("RecordFailure returned error: %v", err)
+		}
+	}
+	if err := throttle.Reset("jake@jake.jake", "1.2.3.4"); err != nil {
+		t.Fatalf("Reset returned error: %v", err)
+	}
+
+	allowed, _, err := throttle.Allow("jake@jake.jake", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+	if !allowed {
+		t.Fatal("expected Allow to succeed after Reset")
+	}
+}
+
+func TestLoginThrottleBackoffGrowsExponentially(t *testing.T) {
+	throttle := NewLoginThrottle(NewMemory(), 1, time.Second)
+
+	_, first, err := throttle.RecordFailure("jake@jake.jake", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("RecordFailure returned error: %v", err)
+	}
+	_, second, err := throttle.RecordFailure("jake@jake.jake", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("RecordFailure returned error: %v", err)
+	}
+
+	if second <= first {
+		t.Fatalf("backoff did not grow: first=%v second=%v", first, second)
+	}
+}
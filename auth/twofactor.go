@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// TwoFactor issues and verifies short-lived six-digit email codes: the
+// optional second factor for login, and the code a password reset is
+// authorized with. The two share the same random-code/store machinery
+// but live in separate keyspaces (via codeKey/resetCodeKey) so a code
+// issued for one can't be replayed against the other.
+type TwoFactor struct {
+	store  Store
+	mailer Mailer
+	ttl    time.Duration
+}
+
+func NewTwoFactor(store Store, mailer Mailer, ttl time.Duration) *TwoFactor {
+	return &TwoFactor{store: store, mailer: mailer, ttl: ttl}
+}
+
+// Issue generates a login code for email, caches it, and emails it via
+// the configured Mailer.
+func (t *TwoFactor) Issue(email string) error {
+	return t.issue(codeKey(email), email, "Your login code")
+}
+
+// Verify checks code against the login code issued for email, consuming
+// it on success so it cannot be replayed.
+func (t *TwoFactor) Verify(email, code string) (bool, error) {
+	return t.verify(codeKey(email), code)
+}
+
+// IssuePasswordReset generates a password-reset code for email, caches
+// it, and emails it via the configured Mailer.
+func (t *TwoFactor) IssuePasswordReset(email string) error {
+	return t.issue(resetCodeKey(email), email, "Your password reset code")
+}
+
+// VerifyPasswordReset checks code against the password-reset code
+// issued for email, consuming it on success so it cannot be replayed.
+func (t *TwoFactor) VerifyPasswordReset(email, code string) (bool, error) {
+	return t.verify(resetCodeKey(email), code)
+}
+
+func (t *TwoFactor) issue(key, email, subject string) error {
+	code, err := randomCode()
+	if err != nil {
+		return err
+	}
+	if err := t.store.Set(key, code, t.ttl); err != nil {
+		return err
+	}
+	return t.mailer.Send(email, subject, fmt.Sprintf("Your verification code is %s. It expires in %s.", code, t.ttl))
+}
+
+func (t *TwoFactor) verify(key, code string) (bool, error) {
+	stored, ok, err := t.store.Get(key)
+	if err != nil || !ok {
+		return false, err
+	}
+	if stored != code {
+		return false, nil
+	}
+	return true, t.store.Delete(key)
+}
+
+func codeKey(email string) string      { return "2fa:" + email }
+func resetCodeKey(email string) string { return "2fa:reset:" + email }
+
+func randomCode() (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	n := (int(b[0])<<16 | int(b[1])<<8 | int(b[2])) % 1000000
+	return fmt.Sprintf("%06d", n), nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeMailer struct {
+	to, subject, body string
+}
+
+func (f *fakeMailer) Send(to, subject, body string) error {
+	f.to, f.subject, f.body = to, subject, body
+	return nil
+}
+
+func TestTwoFactorIssueAndVerify(t *testing.T) {
+	mailer := &fakeMailer{}
+	store := NewMemory()
+	tf := NewTwoFactor(store, mailer, time.Minute)
+
+	if err := tf.Issue("jake@jake.jake"); err != nil {
+		t.Fatalf("Issue returned error: %v", err)
+	}
+	if mailer.to != "jake@jake.jake" {
+		t.Fatalf("mailer.to = %q, want jake@jake.jake", mailer.to)
+	}
+
+	code, ok, err := store.Get(codeKey("jake@jake.jake"))
+	if err != nil || !ok {
+		t.Fatalf("expected a cached code, got ok=%v err=%v", ok, err)
+	}
+
+	ok, err = tf.Verify("jake@jake.jake", code)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if !ok {
+		t.Fatal("Verify() = false, want true for the issued code")
+	}
+
+	ok, err = tf.Verify("jake@jake.jake", code)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if ok {
+		t.Fatal("Verify() = true, want false after the code was already consumed")
+	}
+}
+
+func TestTwoFactorVerifyRejectsWrongCode(t *testing.T) {
+	tf := NewTwoFactor(NewMemory(), &fakeMailer{}, time.Minute)
+	if err := tf.Issue("jake@jake.jake"); err != nil {
+		t.Fatalf("Issue returned error: %v", err)
+	}
+
+	ok, err := tf.Verify("jake@jake.jake", "000000")
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if ok {
+		t.Fatal("Verify() = true, want false for a wrong code")
+	}
+}
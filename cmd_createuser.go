@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sawirricardo/realworld-go/config"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func createUserCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "createuser",
+		Usage:     "create a user directly in the database, prompting for a password",
+		ArgsUsage: "<username>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "email", Required: true, Usage: "email address for the new user"},
+		},
+		Action: func(c *cli.Context) error {
+			username := c.Args().First()
+			if username == "" {
+				return fmt.Errorf("usage: realworld-go createuser [--email EMAIL] <username>")
+			}
+
+			password, err := promptPassword()
+			if err != nil {
+				return fmt.Errorf("reading password: %w", err)
+			}
+
+			app, err := NewApp(config.Load())
+			if err != nil {
+				return fmt.Errorf("connecting to database: %w", err)
+			}
+
+			user, err := app.Users.Create(username, c.String("email"), password)
+			if err != nil {
+				return fmt.Errorf("creating user: %w", err)
+			}
+
+			fmt.Printf("created user %q (id %d)\n", user.Username, user.ID)
+			return nil
+		},
+	}
+}
+
+// promptPassword reads a password from the controlling terminal without
+// echoing it, confirming it matches a second entry.
+func promptPassword() (string, error) {
+	fmt.Print("Password: ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Print("Confirm password: ")
+	confirm, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+
+	if string(password) != string(confirm) {
+		return "", fmt.Errorf("passwords do not match")
+	}
+
+	return string(password), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sawirricardo/realworld-go/config"
+	"github.com/sawirricardo/realworld-go/migration"
+	"github.com/sawirricardo/realworld-go/model"
+	"github.com/urfave/cli/v2"
+)
+
+func migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "create or update the database schema",
+		Action: func(c *cli.Context) error {
+			app, err := NewApp(config.Load())
+			if err != nil {
+				return fmt.Errorf("connecting to database: %w", err)
+			}
+
+			if err := app.DB.AutoMigrate(
+				&model.User{},
+				&model.Article{},
+				&model.Comment{},
+				&model.Tag{},
+				&model.Follower{},
+				&model.RemoteActor{},
+			); err != nil {
+				return fmt.Errorf("auto-migrating: %w", err)
+			}
+
+			if err := migration.NewRunner(app.DB, "db/migrations").Up(); err != nil {
+				return fmt.Errorf("applying migrations: %w", err)
+			}
+
+			fmt.Println("database is up to date")
+			return nil
+		},
+	}
+}
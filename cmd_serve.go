@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sawirricardo/realworld-go/config"
+	"github.com/urfave/cli/v2"
+)
+
+func serveCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "run the HTTP server",
+		Action: func(c *cli.Context) error {
+			app, err := NewApp(config.Load())
+			if err != nil {
+				return fmt.Errorf("connecting to database: %w", err)
+			}
+			return app.Routes().Run(":" + app.Config.Port)
+		},
+	}
+}
@@ -0,0 +1,110 @@
+// Package config loads application configuration from the environment so
+// it can be varied per deployment instead of baked into the binary.
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds everything main needs to wire up the App container.
+type Config struct {
+	DBUsername string
+	DBPassword string
+	DBHost     string
+	DBPort     string
+	DBDatabase string
+
+	Port string
+
+	AppURL string // public base URL used to build actor IDs and links; derived from the request host if empty
+
+	JWTSecret   string
+	JWTTokenTTL time.Duration
+
+	// SessionBackend selects the auth.Store handlers validate sessions and
+	// throttle logins against: "memory" (default, single-process) or
+	// "redis".
+	SessionBackend string
+	RedisAddr      string
+	RedisPassword  string
+	RedisDB        int
+
+	LoginMaxAttempts int           // failed logins allowed before an account/IP is locked
+	LoginLockBase    time.Duration // lock duration after the first attempt past LoginMaxAttempts; doubles per extra failure
+
+	TwoFactorEnabled bool // require an emailed code as a second factor on login
+	TwoFactorTTL     time.Duration
+
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// Load reads configuration from the environment (via a loaded .env file,
+// see godotenv in main), falling back to sane local-dev defaults.
+func Load() *Config {
+	return &Config{
+		DBUsername: os.Getenv("DB_USERNAME"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		DBHost:     os.Getenv("DB_HOST"),
+		DBPort:     os.Getenv("DB_PORT"),
+		DBDatabase: os.Getenv("DB_DATABASE"),
+
+		Port: envOrDefault("PORT", "9001"),
+
+		AppURL: os.Getenv("APP_URL"),
+
+		JWTSecret:   os.Getenv("JWT_SECRET"),
+		JWTTokenTTL: envDurationOrDefault("JWT_TOKEN_TTL", 15*time.Minute),
+
+		SessionBackend: envOrDefault("SESSION_BACKEND", "memory"),
+		RedisAddr:      envOrDefault("REDIS_ADDR", "127.0.0.1:6379"),
+		RedisPassword:  os.Getenv("REDIS_PASSWORD"),
+		RedisDB:        envIntOrDefault("REDIS_DB", 0),
+
+		LoginMaxAttempts: envIntOrDefault("LOGIN_MAX_ATTEMPTS", 5),
+		LoginLockBase:    envDurationOrDefault("LOGIN_LOCK_BASE", 30*time.Second),
+
+		TwoFactorEnabled: os.Getenv("TWO_FACTOR_ENABLED") == "true",
+		TwoFactorTTL:     envDurationOrDefault("TWO_FACTOR_TTL", 10*time.Minute),
+
+		SMTPAddr:     envOrDefault("SMTP_ADDR", "127.0.0.1:25"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     envOrDefault("SMTP_FROM", "noreply@realworld.local"),
+	}
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envIntOrDefault(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func envDurationOrDefault(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	seconds, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
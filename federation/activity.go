@@ -0,0 +1,164 @@
+package federation
+
+import "net/url"
+
+// ContextActivityStreams is the JSON-LD context every outgoing object and
+// activity is tagged with.
+const ContextActivityStreams = "https://www.w3.org/ns/activitystreams"
+
+// ActivityContentType is the content type ActivityPub actors and activities
+// are served and delivered with.
+const ActivityContentType = "application/activity+json"
+
+// PublicKey is the security-vocab public key block embedded in an Actor.
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Actor is the minimal ActivityPub actor document served at
+// GET /users/:username.
+type Actor struct {
+	Context           []string  `json:"@context"`
+	ID                string    `json:"id"`
+	Type              string    `json:"type"`
+	PreferredUsername string    `json:"preferredUsername"`
+	Name              string    `json:"name,omitempty"`
+	Summary           string    `json:"summary,omitempty"`
+	Inbox             string    `json:"inbox"`
+	Outbox            string    `json:"outbox"`
+	Followers         string    `json:"followers"`
+	Following         string    `json:"following"`
+	PublicKey         PublicKey `json:"publicKey"`
+}
+
+// NewActor builds the actor document for username, rooted at baseURL
+// (e.g. "https://example.com").
+func NewActor(baseURL, username, name, summary, publicKeyPEM string) Actor {
+	id := baseURL + "/users/" + username
+	return Actor{
+		Context:           []string{ContextActivityStreams, "https://w3id.org/security/v1"},
+		ID:                id,
+		Type:              "Person",
+		PreferredUsername: username,
+		Name:              name,
+		Summary:           summary,
+		Inbox:             id + "/inbox",
+		Outbox:            id + "/outbox",
+		Followers:         id + "/followers",
+		Following:         id + "/following",
+		PublicKey: PublicKey{
+			ID:           id + "#main-key",
+			Owner:        id,
+			PublicKeyPem: publicKeyPEM,
+		},
+	}
+}
+
+// WebfingerLink is a single entry in a WebfingerResponse's links array.
+type WebfingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+// WebfingerResponse is the JRD served at /.well-known/webfinger.
+type WebfingerResponse struct {
+	Subject string          `json:"subject"`
+	Links   []WebfingerLink `json:"links"`
+}
+
+// NewWebfingerResponse builds the webfinger response pointing at the
+// actor document for username.
+func NewWebfingerResponse(baseURL, username string) WebfingerResponse {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return WebfingerResponse{
+		Subject: "acct:" + username + "@" + host,
+		Links: []WebfingerLink{
+			{Rel: "self", Type: ActivityContentType, Href: baseURL + "/users/" + username},
+		},
+	}
+}
+
+// Tag is an ActivityStreams hashtag entry attached to an article object.
+type Tag struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// ArticleObject is the ActivityStreams representation of a RealWorld
+// article, embedded as the object of a Create activity.
+type ArticleObject struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	AttributedTo string `json:"attributedTo"`
+	Name         string `json:"name"`
+	Summary      string `json:"summary,omitempty"`
+	Content      string `json:"content"`
+	Tag          []Tag  `json:"tag,omitempty"`
+	Published    string `json:"published,omitempty"`
+}
+
+// Activity is the generic envelope used for Create, Follow, Undo and
+// Accept activities.
+type Activity struct {
+	Context []string    `json:"@context"`
+	ID      string      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Actor   string      `json:"actor"`
+	Object  interface{} `json:"object"`
+	To      []string    `json:"to,omitempty"`
+	Cc      []string    `json:"cc,omitempty"`
+}
+
+// NewCreateArticleActivity wraps article as a Create activity addressed to
+// the given follower inboxes (via the public "to" collection).
+func NewCreateArticleActivity(activityID, actorID string, article ArticleObject) Activity {
+	return Activity{
+		Context: []string{ContextActivityStreams},
+		ID:      activityID,
+		Type:    "Create",
+		Actor:   actorID,
+		Object:  article,
+		To:      []string{ContextActivityStreams + "#Public"},
+	}
+}
+
+// NewFollowActivity builds a Follow activity from actorID targeting the
+// remote actor at objectID.
+func NewFollowActivity(activityID, actorID, objectID string) Activity {
+	return Activity{
+		Context: []string{ContextActivityStreams},
+		ID:      activityID,
+		Type:    "Follow",
+		Actor:   actorID,
+		Object:  objectID,
+	}
+}
+
+// NewUndoActivity wraps a previously sent Follow activity in an Undo,
+// used to unfollow a remote actor.
+func NewUndoActivity(activityID, actorID string, follow Activity) Activity {
+	return Activity{
+		Context: []string{ContextActivityStreams},
+		ID:      activityID,
+		Type:    "Undo",
+		Actor:   actorID,
+		Object:  follow,
+	}
+}
+
+// NewAcceptActivity acknowledges an inbound Follow request.
+func NewAcceptActivity(activityID, actorID string, follow interface{}) Activity {
+	return Activity{
+		Context: []string{ContextActivityStreams},
+		ID:      activityID,
+		Type:    "Accept",
+		Actor:   actorID,
+		Object:  follow,
+	}
+}
@@ -0,0 +1,91 @@
+package federation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// httpClient is used for every outbound federation request (WebFinger
+// lookups, actor fetches, inbox deliveries). Its transport dials
+// through safeDialContext so none of those requests can be pointed at
+// loopback, link-local or private infrastructure.
+var httpClient = &http.Client{
+	Timeout:   10 * time.Second,
+	Transport: &http.Transport{DialContext: safeDialContext},
+}
+
+// Get performs a GET through the SSRF-guarded httpClient, for outbound
+// federation requests that don't go through Deliver or FetchActor (e.g.
+// a WebFinger lookup). Callers should still validate the URL with
+// ValidateOutboundURL first for a clear error and, where relevant,
+// domain pinning.
+func Get(url string) (*http.Response, error) {
+	return httpClient.Get(url)
+}
+
+// Deliver POSTs activity to the remote inbox at inboxURL, signed as
+// actorID using privateKeyPEM.
+func Deliver(activity interface{}, inboxURL, actorID, privateKeyPEM string) error {
+	if err := ValidateOutboundURL(inboxURL, ""); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, inboxURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ActivityContentType)
+	req.Header.Set("Accept", ActivityContentType)
+
+	if err := SignRequest(req, body, KeyID(actorID), privateKeyPEM); err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("federation: delivery to %s failed with status %d", inboxURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// FetchActor retrieves and decodes the remote actor document at actorID.
+func FetchActor(actorID string) (Actor, error) {
+	var actor Actor
+
+	if err := ValidateOutboundURL(actorID, ""); err != nil {
+		return actor, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, actorID, nil)
+	if err != nil {
+		return actor, err
+	}
+	req.Header.Set("Accept", ActivityContentType)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return actor, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return actor, fmt.Errorf("federation: fetching actor %s failed with status %d", actorID, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&actor); err != nil {
+		return actor, err
+	}
+	return actor, nil
+}
@@ -0,0 +1,94 @@
+package federation
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSignRequestVerifyRequestRoundTrip(t *testing.T) {
+	privateKeyPEM, publicKeyPEM, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair returned error: %v", err)
+	}
+
+	body := []byte(`{"type":"Follow"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/users/jake/inbox", nil)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	req.Host = "example.com"
+
+	if err := SignRequest(req, body, KeyID("https://example.com/users/jake"), privateKeyPEM); err != nil {
+		t.Fatalf("SignRequest returned error: %v", err)
+	}
+
+	if err := VerifyRequest(req, body, publicKeyPEM); err != nil {
+		t.Fatalf("VerifyRequest returned error: %v", err)
+	}
+}
+
+func TestVerifyRequestRejectsTamperedBody(t *testing.T) {
+	privateKeyPEM, publicKeyPEM, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair returned error: %v", err)
+	}
+
+	body := []byte(`{"type":"Follow"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/users/jake/inbox", nil)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	req.Host = "example.com"
+
+	if err := SignRequest(req, body, KeyID("https://example.com/users/jake"), privateKeyPEM); err != nil {
+		t.Fatalf("SignRequest returned error: %v", err)
+	}
+
+	if err := VerifyRequest(req, []byte(`{"type":"Undo"}`), publicKeyPEM); err == nil {
+		t.Fatal("VerifyRequest succeeded against a tampered body, want an error")
+	}
+}
+
+func TestVerifyRequestRejectsWrongKey(t *testing.T) {
+	privateKeyPEM, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair returned error: %v", err)
+	}
+	_, otherPublicKeyPEM, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair returned error: %v", err)
+	}
+
+	body := []byte(`{"type":"Follow"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/users/jake/inbox", nil)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	req.Host = "example.com"
+
+	if err := SignRequest(req, body, KeyID("https://example.com/users/jake"), privateKeyPEM); err != nil {
+		t.Fatalf("SignRequest returned error: %v", err)
+	}
+
+	if err := VerifyRequest(req, body, otherPublicKeyPEM); err == nil {
+		t.Fatal("VerifyRequest succeeded against the wrong public key, want an error")
+	}
+}
+
+func TestVerifyRequestRejectsMissingSignature(t *testing.T) {
+	_, publicKeyPEM, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair returned error: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/users/jake/inbox", nil)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+
+	err = VerifyRequest(req, nil, publicKeyPEM)
+	if err == nil || !strings.Contains(err.Error(), "missing Signature header") {
+		t.Fatalf("VerifyRequest error = %v, want a missing-signature error", err)
+	}
+}
@@ -0,0 +1,77 @@
+package federation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// safeDialContext backs httpClient's transport. It resolves the dial
+// address itself and refuses to connect if any resolved IP is loopback,
+// link-local, unspecified, private or multicast, then dials that IP
+// directly rather than handing the hostname to net.Dial — so the check
+// can't be bypassed by DNS answering differently between validation and
+// connection (DNS rebinding).
+func safeDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("federation: resolving %q: %w", host, err)
+	}
+
+	var dialIP net.IP
+	for _, ip := range ips {
+		if isDisallowedIP(ip) {
+			return nil, fmt.Errorf("federation: %q resolves to a non-routable address (%s)", host, ip)
+		}
+		if dialIP == nil {
+			dialIP = ip
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	return dialer.DialContext(ctx, network, net.JoinHostPort(dialIP.String(), port))
+}
+
+// isDisallowedIP reports whether ip is loopback, link-local, multicast,
+// unspecified, or within a private range — anything that an outbound
+// federation request (WebFinger lookup, actor fetch, inbox delivery)
+// should never be allowed to reach.
+func isDisallowedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsPrivate() || ip.IsMulticast()
+}
+
+// ValidateOutboundURL checks that rawURL is fit to be fetched or
+// delivered to by this server: an https URL with a host. If
+// expectedHost is non-empty, rawURL's host must match it exactly, so a
+// WebFinger-resolved actor or inbox URL can be pinned to the handle's
+// own domain instead of trusting wherever the remote response points.
+// The IP-range check that actually blocks SSRF happens at connection
+// time in safeDialContext, since validating it here separately would
+// leave a window for DNS to answer differently by the time the request
+// is made.
+func ValidateOutboundURL(rawURL, expectedHost string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("federation: invalid URL %q: %w", rawURL, err)
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("federation: %q must use https", rawURL)
+	}
+	host := parsed.Hostname()
+	if host == "" {
+		return fmt.Errorf("federation: %q has no host", rawURL)
+	}
+	if expectedHost != "" && !strings.EqualFold(host, expectedHost) {
+		return fmt.Errorf("federation: %q does not match expected host %q", rawURL, expectedHost)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package federation
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestIsDisallowedIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want bool
+	}{
+		{"public ipv4", "93.184.216.34", false},
+		{"public ipv6", "2606:2800:220:1:248:1893:25c8:1946", false},
+		{"loopback ipv4", "127.0.0.1", true},
+		{"loopback ipv6", "::1", true},
+		{"link-local unicast", "169.254.169.254", true},
+		{"link-local multicast", "224.0.0.251", true},
+		{"unspecified", "0.0.0.0", true},
+		{"private class a", "10.0.0.1", true},
+		{"private class b", "172.16.0.1", true},
+		{"private class c", "192.168.1.1", true},
+		{"multicast", "239.255.255.250", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := net.ParseIP(tt.ip)
+			if ip == nil {
+				t.Fatalf("net.ParseIP(%q) = nil", tt.ip)
+			}
+			if got := isDisallowedIP(ip); got != tt.want {
+				t.Fatalf("isDisallowedIP(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOutboundURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		rawURL       string
+		expectedHost string
+		wantErr      bool
+	}{
+		{"valid https, no pin", "https://example.com/inbox", "", false},
+		{"valid https, matching pin", "https://example.com/inbox", "example.com", false},
+		{"valid https, case-insensitive pin", "https://Example.COM/inbox", "example.com", false},
+		{"rejects http", "http://example.com/inbox", "", true},
+		{"rejects mismatched pin", "https://evil.com/inbox", "example.com", true},
+		{"rejects no host", "https:///inbox", "", true},
+		{"rejects invalid url", "https://%zz", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOutboundURL(tt.rawURL, tt.expectedHost)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateOutboundURL(%q, %q) error = %v, wantErr %v", tt.rawURL, tt.expectedHost, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSafeDialContextRejectsLoopbackTarget(t *testing.T) {
+	if _, err := safeDialContext(context.Background(), "tcp", "127.0.0.1:80"); err == nil {
+		t.Fatal("safeDialContext succeeded dialing loopback, want an error")
+	}
+}
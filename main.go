@@ -1,88 +1,165 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/rs/zerolog"
+	"github.com/sawirricardo/realworld-go/auth"
+	"github.com/sawirricardo/realworld-go/config"
+	"github.com/sawirricardo/realworld-go/federation"
+	"github.com/sawirricardo/realworld-go/middleware"
+	"github.com/sawirricardo/realworld-go/model"
+	"github.com/sawirricardo/realworld-go/service"
+	"github.com/urfave/cli/v2"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
-func main() {
-	initConfig()
+// App is the application container: the one place *gorm.DB, configuration
+// and the service layer are constructed, so handlers never reach for a
+// package-level global. It is built once in main and its handler methods
+// are registered as Gin routes.
+type App struct {
+	DB     *gorm.DB
+	Config *config.Config
+	Logger zerolog.Logger
 
-	r := gin.Default()
-	r.GET("/", func(c *gin.Context) {
-		c.String(200, "Real world application implemented with Go(Golang) By Ricardo Sawir")
-	})
-	r.GET("articles", getArticles)
-	r.POST("articles", createArticle)
-	r.GET("articles/:slug", showArticle)
-	r.PUT("articles/:slug", updateArticle)
-	r.DELETE("articles/:slug", deleteArticle)
+	Users    *service.UserService
+	Articles *service.ArticleService
+	Comments *service.CommentService
+	Profiles *service.ProfileService
 
-	r.POST("articles/:slug/favorite", favoriteArticle)
-	r.DELETE("articles/:slug/favorite", unfavoriteArticle)
+	Sessions  *auth.SessionCache
+	Throttle  *auth.LoginThrottle
+	TwoFactor *auth.TwoFactor // nil unless Config.TwoFactorEnabled
+}
 
-	r.GET("articles/:slug/comments", getComments)
-	r.POST("articles/:slug/comments", createComment)
-	r.DELETE("articles/:slug/comments/:commentId", deleteComment)
+// NewApp opens the single DB connection pool used for the process's
+// lifetime and wires up the service layer on top of it.
+func NewApp(cfg *config.Config) (*App, error) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=Local", cfg.DBUsername, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBDatabase),
+		DefaultStringSize:         191,   // default size for string fields
+		DisableDatetimePrecision:  true,  // disable datetime precision, which not supported before MySQL 5.6
+		DontSupportRenameIndex:    true,  // drop & create when rename index, rename index not supported before MySQL 5.7, MariaDB
+		DontSupportRenameColumn:   true,  // `change` when rename column, rename column not supported before MySQL 8, MariaDB
+		SkipInitializeWithVersion: false, // auto configure based on currently MySQL version
+	}), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
 
-	r.POST("users/login", loginUser)
-	r.POST("users", createUser)
-	r.GET("user", showUser)
-	r.PUT("user", updateUser)
+	store := newAuthStore(cfg)
+
+	var twoFactor *auth.TwoFactor
+	if cfg.TwoFactorEnabled {
+		mailer := auth.NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+		twoFactor = auth.NewTwoFactor(store, mailer, cfg.TwoFactorTTL)
+	}
 
-	r.GET("profiles/:username", showProfile)
-	r.POST("profiles/:username/follow", followProfile)
-	r.DELETE("profiles/:username/follow", unfollowProfile)
+	return &App{
+		DB:     db,
+		Config: cfg,
+		Logger: zerolog.New(os.Stdout).With().Timestamp().Logger(),
 
-	r.GET("tags", getTags)
-	r.Run(":9001") // listen and serve on 0.0.0.0:8080
+		Users:    service.NewUserService(db),
+		Articles: service.NewArticleService(db),
+		Comments: service.NewCommentService(db),
+		Profiles: service.NewProfileService(db),
+
+		Sessions:  auth.NewSessionCache(store, cfg.JWTTokenTTL),
+		Throttle:  auth.NewLoginThrottle(store, cfg.LoginMaxAttempts, cfg.LoginLockBase),
+		TwoFactor: twoFactor,
+	}, nil
 }
 
-func initConfig() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+// newAuthStore builds the auth.Store session caching and login throttling
+// share, per Config.SessionBackend.
+func newAuthStore(cfg *config.Config) auth.Store {
+	if cfg.SessionBackend == "redis" {
+		return auth.NewRedis(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
 	}
+	return auth.NewMemory()
 }
 
-func getDB() *gorm.DB {
+func main() {
+	_ = godotenv.Load() // optional: operators may configure purely via the environment
 
-	db, err := gorm.Open(mysql.New(mysql.Config{
-		DSN:                       fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=Local", os.Getenv("DB_USERNAME"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_DATABASE")), // data source name
-		DefaultStringSize:         191,                                                                                                                                                                                                // default size for string fields
-		DisableDatetimePrecision:  true,                                                                                                                                                                                               // disable datetime precision, which not supported before MySQL 5.6
-		DontSupportRenameIndex:    true,                                                                                                                                                                                               // drop & create when rename index, rename index not supported before MySQL 5.7, MariaDB
-		DontSupportRenameColumn:   true,                                                                                                                                                                                               // `change` when rename column, rename column not supported before MySQL 8, MariaDB
-		SkipInitializeWithVersion: false,                                                                                                                                                                                              // auto configure based on currently MySQL version
-	}), &gorm.Config{})
-	if err != nil {
-		panic(err)
+	cliApp := &cli.App{
+		Name:  "realworld-go",
+		Usage: "RealWorld API server and operator tooling",
+		Commands: []*cli.Command{
+			initCommand(),
+			migrateCommand(),
+			serveCommand(),
+			createUserCommand(),
+		},
 	}
 
-	return db
+	if err := cliApp.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
 }
 
-type User struct {
-	ID              uint64    `json:"-"`
-	Username        string    `json:"username"`
-	Email           string    `json:"email"`
-	Password        string    `json:"-"`
-	Bio             string    `json:"bio"`
-	Image           string    `json:"image"`
-	EmailVerifiedAt time.Time `json:"-"`
-	CreatedAt       time.Time `json:"-"`
-	UpdatedAt       time.Time `json:"-"`
+// Routes builds the Gin engine with every HTTP route wired to its App
+// method. Split out from the serve command so it stays reusable (e.g. by
+// future integration tests).
+func (a *App) Routes() *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Recovery(), middleware.AccessLog(a.Logger))
+
+	r.GET("/", func(c *gin.Context) {
+		c.String(200, "Real world application implemented with Go(Golang) By Ricardo Sawir")
+	})
+
+	optionalAuth := r.Group("/")
+	optionalAuth.Use(middleware.OptionalAuth(a.Config.JWTSecret, a.Users, a.Sessions))
+	optionalAuth.GET("articles", a.getArticles)
+	optionalAuth.GET("articles/:slug", a.showArticle)
+	optionalAuth.GET("articles/:slug/comments", a.getComments)
+	optionalAuth.GET("profiles/:username", a.showProfile)
+
+	requiredAuth := r.Group("/")
+	requiredAuth.Use(middleware.JWTAuth(a.Config.JWTSecret, a.Users, a.Sessions))
+	requiredAuth.GET("articles/feed", a.getArticlesFeed)
+	requiredAuth.POST("articles", a.createArticle)
+	requiredAuth.PUT("articles/:slug", a.updateArticle)
+	requiredAuth.DELETE("articles/:slug", a.deleteArticle)
+	requiredAuth.POST("articles/:slug/favorite", a.favoriteArticle)
+	requiredAuth.DELETE("articles/:slug/favorite", a.unfavoriteArticle)
+	requiredAuth.POST("articles/:slug/comments", a.createComment)
+	requiredAuth.DELETE("articles/:slug/comments/:commentId", a.deleteComment)
+	requiredAuth.GET("user", a.showUser)
+	requiredAuth.PUT("user", a.updateUser)
+	requiredAuth.POST("profiles/:username/follow", a.followProfile)
+	requiredAuth.DELETE("profiles/:username/follow", a.unfollowProfile)
+
+	r.POST("users/login", a.loginUser)
+	r.POST("users/login/verify", a.verifyLoginTwoFactor)
+	r.POST("users/password/forgot", a.forgotPassword)
+	r.POST("users/password/reset", a.resetPassword)
+	r.POST("users", a.createUser)
+
+	r.GET("tags", a.getTags)
+
+	r.GET("/.well-known/webfinger", a.webfingerHandler)
+	r.GET("users/:username", a.actorHandler)
+	r.POST("users/:username/inbox", a.inboxHandler)
+	r.GET("users/:username/outbox", a.outboxHandler)
+
+	return r
 }
 
 type LoginRequest struct {
@@ -91,7 +168,7 @@ type LoginRequest struct {
 
 type LoginUser struct {
 	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required`
+	Password string `json:"password" binding:"required"`
 }
 
 type UserResource struct {
@@ -102,164 +179,614 @@ type UserResource struct {
 	Token    string `json:"token"`
 }
 
-type Profile struct {
-	Username  string `json:"username"`
-	Bio       string `json:"bio"`
-	Image     string `json:"image"`
-	Following bool   `json:"following"`
+type ArticleResource struct {
+	Title          string        `json:"title"`
+	Body           string        `json:"body"`
+	Description    string        `json:"description"`
+	Slug           string        `json:"slug"`
+	Author         model.Profile `json:"author"`
+	Tags           []string      `json:"tagList"`
+	Favorited      bool          `json:"favorited"`
+	FavoritesCount uint          `json:"favoritesCount"`
+	CreatedAt      string        `json:"createdAt"`
+	UpdatedAt      string        `json:"updatedAt"`
 }
 
-type Article struct {
-	ID          uint64    `json:"-"`
-	Title       string    `json:"title"`
-	Body        string    `json:"body"`
-	Description string    `json:"description"`
-	Slug        string    `json:"slug"`
-	UserID      uint64    `json:"-"`
-	User        User      `json:"author"`
-	Tags        []Tag     `json:"tagList" gorm:"many2many:article_tag"`
-	Favoriters  []User    `json:"-" gorm:"many2many:favoriters"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+// newArticleResource shapes article for viewerID (0 for an anonymous
+// caller), who is following the article's author iff following is true.
+func newArticleResource(article model.Article, viewerID uint64, following bool) ArticleResource {
+	tags := make([]string, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	favorited := false
+	for _, favoriter := range article.Favoriters {
+		if viewerID != 0 && favoriter.ID == viewerID {
+			favorited = true
+			break
+		}
+	}
+
+	return ArticleResource{
+		Title:       article.Title,
+		Description: article.Description,
+		Tags:        tags,
+		Body:        article.Body,
+		Author: model.Profile{
+			Username:  article.User.Username,
+			Bio:       article.User.Bio,
+			Image:     article.User.Image,
+			Following: following,
+		},
+		Slug:           article.Slug,
+		Favorited:      favorited,
+		FavoritesCount: uint(len(article.Favoriters)),
+		CreatedAt:      article.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      article.UpdatedAt.Format(time.RFC3339),
+	}
 }
 
-type ArticleResource struct {
-	Title          string   `json:"title"`
-	Body           string   `json:"body"`
-	Description    string   `json:"description"`
-	Slug           string   `json:"slug"`
-	User           User     `json:"author"`
-	Tags           []string `json:"tagList"`
-	Favorited      bool     `json:"favorited"`
-	FavoritesCount uint     `json:"favoritesCount"`
-	CreatedAt      string   `json:"createdAt"`
-	UpdatedAt      string   `json:"updatedAt"`
-}
-
-type Comment struct {
-	ID        uint64    `json:"id"`
-	Body      string    `json:"body"`
-	UserID    uint64    `json:"author"`
-	ArticleID uint64    `json:"-"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-}
-
-type Tag struct {
-	ID        uint64    `json:"-"`
-	Name      string    `json:"name"`
-	Slug      string    `json:"-"`
-	CreatedAt time.Time `json:"-"`
-	UpdatedAt time.Time `json:"-"`
-}
-
-func getArticles(c *gin.Context) {
-	db := getDB()
-	var articles []Article
-	var articleCollection []ArticleResource
-	db.Preload("User").Preload("Tags").Find(&articles)
+// currentViewerID returns the authenticated caller's id, or 0 if the
+// request is anonymous.
+func currentViewerID(c *gin.Context) uint64 {
+	if user, ok := middleware.CurrentUser(c); ok {
+		return user.ID
+	}
+	return 0
+}
+
+// queryInt reads key as an integer query param, falling back to
+// fallback if it is missing or not a valid integer.
+func queryInt(c *gin.Context, key string, fallback int) int {
+	value, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// articlesResponse shapes articles into the {"articles": ..., "articlesCount": ...}
+// body shared by getArticles and getArticlesFeed, batching the
+// viewer's following state across every author in one query.
+func (a *App) articlesResponse(c *gin.Context, articles []model.Article) (gin.H, error) {
+	viewerID := currentViewerID(c)
+
+	authorIDs := make([]uint64, 0, len(articles))
 	for _, article := range articles {
-		var tags []string
+		authorIDs = append(authorIDs, article.UserID)
+	}
+	following, err := a.Profiles.FollowingSet(viewerID, authorIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, tag := range article.Tags {
-			tags = append(tags, tag.Name)
-		}
+	collection := make([]ArticleResource, 0, len(articles))
+	for _, article := range articles {
+		collection = append(collection, newArticleResource(article, viewerID, following[article.UserID]))
+	}
+	return gin.H{"articles": collection, "articlesCount": len(collection)}, nil
+}
 
-		articleResource := ArticleResource{
-			Title:       article.Title,
-			Description: article.Description,
-			Tags:        tags,
-			Body:        article.Body,
-			User:        article.User,
-			CreatedAt:   article.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   article.UpdatedAt.Format(time.RFC3339),
-		}
+func (a *App) getArticles(c *gin.Context) {
+	filter := service.ArticleFilter{
+		Tag:       c.Query("tag"),
+		Author:    c.Query("author"),
+		Favorited: c.Query("favorited"),
+		Limit:     queryInt(c, "limit", 20),
+		Offset:    queryInt(c, "offset", 0),
+	}
 
-		articleCollection = append(articleCollection, articleResource)
+	articles, err := a.Articles.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
 	}
 
-	c.JSON(200, gin.H{
-		"articles":      articleCollection,
-		"articlesCount": len(articles),
-	})
+	body, err := a.articlesResponse(c, articles)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	c.JSON(200, body)
 }
 
-func createArticle(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"article": "pong",
-	})
+// getArticlesFeed serves GET /articles/feed: articles by users the
+// caller follows, most recently created first.
+func (a *App) getArticlesFeed(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	articles, err := a.Articles.Feed(user.ID, queryInt(c, "limit", 20), queryInt(c, "offset", 0))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	body, err := a.articlesResponse(c, articles)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	c.JSON(200, body)
 }
 
-func deleteArticle(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"message": "pong",
-	})
+type CreateArticleRequest struct {
+	Data CreateArticleData `json:"article" binding:"required"`
 }
 
-func showArticle(c *gin.Context) {
-	slug := c.Param("slug")
-	db := getDB()
-	var article Article
-	db.Where("slug=?", slug).Preload("Tags").First(&article)
-	c.JSON(200, gin.H{
-		"article": article,
-	})
+type CreateArticleData struct {
+	Title       string   `json:"title" binding:"required"`
+	Description string   `json:"description"`
+	Body        string   `json:"body" binding:"required"`
+	Tags        []string `json:"tagList"`
 }
 
-func updateArticle(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"message": "pong",
-	})
+func (a *App) createArticle(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var req CreateArticleRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	article, err := a.Articles.Create(user.ID, req.Data.Title, req.Data.Description, req.Data.Body, req.Data.Tags, a.baseURL(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	c.JSON(200, gin.H{"article": newArticleResource(*article, user.ID, false)})
 }
 
-func favoriteArticle(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"message": "pong",
-	})
+func (a *App) showArticle(c *gin.Context) {
+	article, err := a.Articles.Get(c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"article not found"}}})
+		return
+	}
+
+	resource, err := a.articleResource(*article, currentViewerID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	c.JSON(200, gin.H{"article": resource})
 }
 
-func unfavoriteArticle(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"message": "pong",
-	})
+type UpdateArticleRequest struct {
+	Data UpdateArticleData `json:"article" binding:"required"`
 }
 
-func getComments(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"comments": "pong",
-	})
+type UpdateArticleData struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Body        string `json:"body"`
 }
 
-func createComment(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"comment": "pong",
-	})
+func (a *App) updateArticle(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var req UpdateArticleRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	article, err := a.Articles.Update(c.Param("slug"), user.ID, req.Data.Title, req.Data.Description, req.Data.Body)
+	if err != nil {
+		a.respondArticleError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"article": newArticleResource(*article, user.ID, false)})
 }
 
-func deleteComment(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"comment": "pong",
-	})
+func (a *App) deleteArticle(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	if err := a.Articles.Delete(c.Param("slug"), user.ID); err != nil {
+		a.respondArticleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (a *App) favoriteArticle(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	article, err := a.Articles.Favorite(c.Param("slug"), user.ID)
+	if err != nil {
+		a.respondArticleError(c, err)
+		return
+	}
+
+	resource, err := a.articleResource(*article, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	c.JSON(200, gin.H{"article": resource})
+}
+
+func (a *App) unfavoriteArticle(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	article, err := a.Articles.Unfavorite(c.Param("slug"), user.ID)
+	if err != nil {
+		a.respondArticleError(c, err)
+		return
+	}
+
+	resource, err := a.articleResource(*article, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	c.JSON(200, gin.H{"article": resource})
+}
+
+// articleResource shapes a single article for viewerID, looking up
+// whether viewerID follows its author. Use articlesResponse instead when
+// shaping a collection, so the following lookup is batched.
+func (a *App) articleResource(article model.Article, viewerID uint64) (ArticleResource, error) {
+	following, err := a.Profiles.FollowingSet(viewerID, []uint64{article.UserID})
+	if err != nil {
+		return ArticleResource{}, err
+	}
+	return newArticleResource(article, viewerID, following[article.UserID]), nil
+}
+
+// respondArticleError maps a service-layer article/comment error to the
+// RealWorld-shaped HTTP response: 403 for ErrForbidden, 404 otherwise
+// (the service layer has no other failure mode for these lookups but a
+// missing row).
+func (a *App) respondArticleError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"errors": gin.H{"body": []string{"not the author"}}})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"not found"}}})
+}
+
+type CommentResource struct {
+	ID        uint64        `json:"id"`
+	Body      string        `json:"body"`
+	Author    model.Profile `json:"author"`
+	CreatedAt string        `json:"createdAt"`
+	UpdatedAt string        `json:"updatedAt"`
+}
+
+func newCommentResource(comment model.Comment, following bool) CommentResource {
+	return CommentResource{
+		ID:   comment.ID,
+		Body: comment.Body,
+		Author: model.Profile{
+			Username:  comment.User.Username,
+			Bio:       comment.User.Bio,
+			Image:     comment.User.Image,
+			Following: following,
+		},
+		CreatedAt: comment.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: comment.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (a *App) getComments(c *gin.Context) {
+	article, err := a.Articles.Get(c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"article not found"}}})
+		return
+	}
+
+	comments, err := a.Comments.List(article.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	viewerID := currentViewerID(c)
+	authorIDs := make([]uint64, 0, len(comments))
+	for _, comment := range comments {
+		authorIDs = append(authorIDs, comment.UserID)
+	}
+	following, err := a.Profiles.FollowingSet(viewerID, authorIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	collection := make([]CommentResource, 0, len(comments))
+	for _, comment := range comments {
+		collection = append(collection, newCommentResource(comment, following[comment.UserID]))
+	}
+	c.JSON(200, gin.H{"comments": collection})
 }
 
-func loginUser(c *gin.Context) {
+type CreateCommentRequest struct {
+	Data CreateCommentData `json:"comment" binding:"required"`
+}
+
+type CreateCommentData struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (a *App) createComment(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	article, err := a.Articles.Get(c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"article not found"}}})
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	comment, err := a.Comments.Create(article.ID, user.ID, req.Data.Body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	c.JSON(200, gin.H{"comment": newCommentResource(*comment, false)})
+}
+
+func (a *App) deleteComment(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	commentID, err := strconv.ParseUint(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"invalid comment id"}}})
+		return
+	}
+
+	if err := a.Comments.Delete(commentID, user.ID); err != nil {
+		a.respondArticleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (a *App) loginUser(c *gin.Context) {
 	var loginRequest LoginRequest
-	if c.BindJSON(&loginRequest) != nil {
-		panic("Wrong data")
+	if err := c.BindJSON(&loginRequest); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	email, remoteAddr := loginRequest.Data.Email, c.ClientIP()
+
+	allowed, retryAfter, err := a.Throttle.Allow(email, remoteAddr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if !allowed {
+		a.respondLocked(c, retryAfter)
+		return
+	}
+
+	user, err := a.Users.Authenticate(email, loginRequest.Data.Password)
+	if err != nil {
+		if locked, retryAfter, terr := a.Throttle.RecordFailure(email, remoteAddr); terr == nil && locked {
+			a.respondLocked(c, retryAfter)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"wrong username or password"}}})
+		return
+	}
+	if err := a.Throttle.Reset(email, remoteAddr); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	if a.TwoFactor != nil {
+		if err := a.TwoFactor.Issue(user.Email); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{"could not send verification code"}}})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"twoFactorRequired": true})
+		return
+	}
+
+	a.respondWithSession(c, user, remoteAddr)
+}
+
+// verifyLoginTwoFactor completes a login that Config.TwoFactorEnabled left
+// pending, exchanging the emailed code for a token.
+func (a *App) verifyLoginTwoFactor(c *gin.Context) {
+	var req struct {
+		Data struct {
+			Email string `json:"email" binding:"required"`
+			Code  string `json:"code" binding:"required"`
+		} `json:"user" binding:"required"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	if a.TwoFactor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"two-factor login is not enabled"}}})
+		return
+	}
+
+	email, remoteAddr := req.Data.Email, c.ClientIP()
+
+	allowed, retryAfter, err := a.Throttle.Allow(email, remoteAddr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if !allowed {
+		a.respondLocked(c, retryAfter)
+		return
+	}
+
+	ok, err := a.TwoFactor.Verify(email, req.Data.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if !ok {
+		if locked, retryAfter, terr := a.Throttle.RecordFailure(email, remoteAddr); terr == nil && locked {
+			a.respondLocked(c, retryAfter)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"invalid or expired code"}}})
+		return
+	}
+
+	if err := a.Throttle.Reset(email, remoteAddr); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	user, err := a.Users.FindByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"invalid or expired code"}}})
+		return
+	}
+
+	a.respondWithSession(c, user, remoteAddr)
+}
+
+// forgotPassword issues a password-reset code to email's owner, if any.
+// It always responds 202 regardless of whether the email is registered,
+// so the endpoint can't be used to enumerate accounts.
+func (a *App) forgotPassword(c *gin.Context) {
+	var req struct {
+		Data struct {
+			Email string `json:"email" binding:"required"`
+		} `json:"user" binding:"required"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
 	}
 
-	var user User
-	getDB().First(&user, "email = ?", loginRequest.Data.Email)
-	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginRequest.Data.Password))
+	if a.TwoFactor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"password reset is not enabled"}}})
+		return
+	}
+
+	email, remoteAddr := req.Data.Email, c.ClientIP()
+
+	allowed, retryAfter, err := a.Throttle.Allow(email, remoteAddr)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"status":  http.StatusUnauthorized,
-			"message": "wrong username or password",
-		})
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
 	}
-	token, err := CreateToken(user.ID)
+	if !allowed {
+		a.respondLocked(c, retryAfter)
+		return
+	}
+
+	// Every call counts against the throttle, win or lose, since an
+	// unlimited number of forgot-password requests would both spam the
+	// victim's inbox and keep invalidating whatever code they were just
+	// emailed. A mailer error is logged, not surfaced, so it can't be
+	// used to distinguish a registered email from an unregistered one.
+	if user, err := a.Users.FindByEmail(email); err == nil {
+		if err := a.TwoFactor.IssuePasswordReset(user.Email); err != nil {
+			log.Printf("auth: sending password reset code to %s: %v", user.Email, err)
+		}
+	}
+	if locked, retryAfter, terr := a.Throttle.RecordFailure(email, remoteAddr); terr == nil && locked {
+		a.respondLocked(c, retryAfter)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if that email is registered, a reset code has been sent"})
+}
+
+// resetPassword exchanges a password-reset code emailed by
+// forgotPassword for a new password, then logs the user in.
+func (a *App) resetPassword(c *gin.Context) {
+	var req struct {
+		Data struct {
+			Email    string `json:"email" binding:"required"`
+			Code     string `json:"code" binding:"required"`
+			Password string `json:"password" binding:"required"`
+		} `json:"user" binding:"required"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	if a.TwoFactor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"password reset is not enabled"}}})
+		return
+	}
+
+	email, remoteAddr := req.Data.Email, c.ClientIP()
+
+	allowed, retryAfter, err := a.Throttle.Allow(email, remoteAddr)
 	if err != nil {
-		panic("Server error")
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if !allowed {
+		a.respondLocked(c, retryAfter)
+		return
+	}
+
+	ok, err := a.TwoFactor.VerifyPasswordReset(email, req.Data.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	if !ok {
+		if locked, retryAfter, terr := a.Throttle.RecordFailure(email, remoteAddr); terr == nil && locked {
+			a.respondLocked(c, retryAfter)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"invalid or expired code"}}})
+		return
+	}
+
+	if err := a.Throttle.Reset(email, remoteAddr); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	user, err := a.Users.FindByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{"invalid or expired code"}}})
+		return
+	}
+	if _, err := a.Users.Update(user.ID, "", "", req.Data.Password, "", ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	a.respondWithSession(c, user, remoteAddr)
+}
+
+// respondLocked writes the 429 response a throttled login or verification
+// attempt gets, with a Retry-After header per RFC 7231.
+func (a *App) respondLocked(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", fmt.Sprintf("%d", int(retryAfter.Seconds())))
+	c.JSON(http.StatusTooManyRequests, gin.H{"errors": gin.H{"body": []string{"too many failed attempts, try again later"}}})
+}
+
+// respondWithSession caches a session for user, issues a JWT carrying its
+// sid, and writes the usual {"user": ...} response.
+func (a *App) respondWithSession(c *gin.Context, user *model.User, remoteAddr string) {
+	sid, err := a.Sessions.Put(user.ID, remoteAddr)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{"could not start session"}}})
+		return
+	}
+
+	token, err := a.CreateToken(user.ID, sid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{"could not issue token"}}})
+		return
 	}
 
 	c.JSON(200, gin.H{
@@ -273,106 +800,290 @@ func loginUser(c *gin.Context) {
 	})
 }
 
-func CreateToken(userid uint64) (string, error) {
-	var err error
-	//Creating Access Token
+// CreateToken issues a signed JWT for userID using the app's configured
+// secret and token TTL, embedding sid (from Sessions.Put) so the session
+// can be looked up or revoked without a DB roundtrip.
+func (a *App) CreateToken(userID uint64, sid string) (string, error) {
 	atClaims := jwt.MapClaims{}
 	atClaims["authorized"] = true
-	atClaims["user_id"] = userid
-	atClaims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+	atClaims["user_id"] = userID
+	atClaims["sid"] = sid
+	atClaims["exp"] = time.Now().Add(a.Config.JWTTokenTTL).Unix()
 	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
-	token, err := at.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return at.SignedString([]byte(a.Config.JWTSecret))
+}
+
+type CreateUserRequest struct {
+	Data CreateUserData `json:"user" binding:"required"`
+}
+
+type CreateUserData struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (a *App) createUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	user, err := a.Users.Create(req.Data.Username, req.Data.Email, req.Data.Password)
 	if err != nil {
-		return "", err
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
 	}
-	return token, nil
+
+	a.respondWithSession(c, user, c.ClientIP())
 }
 
-func createUser(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"comment": "pong",
-	})
+type UpdateUserRequest struct {
+	Data UpdateUserData `json:"user" binding:"required"`
 }
 
-func updateUser(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"comment": "pong",
-	})
+type UpdateUserData struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Bio      string `json:"bio"`
+	Image    string `json:"image"`
 }
 
-func showUser(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"comment": "pong",
-	})
+func (a *App) updateUser(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var req UpdateUserRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	updated, err := a.Users.Update(user.ID, req.Data.Username, req.Data.Email, req.Data.Password, req.Data.Bio, req.Data.Image)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	c.JSON(200, gin.H{"user": &UserResource{
+		Username: updated.Username,
+		Email:    updated.Email,
+		Bio:      updated.Bio,
+		Image:    updated.Image,
+		Token:    bearerToken(c),
+	}})
 }
 
-func showProfile(c *gin.Context) {
-	username := c.Param("username")
-	var user User
-	db := getDB()
-	db.Where("username = ?", username).First(&user)
-	profile := &Profile{Username: user.Username, Bio: user.Bio, Image: user.Image}
-	c.JSON(200, gin.H{
-		"profile": profile,
-	})
+func (a *App) showUser(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	c.JSON(200, gin.H{"user": &UserResource{
+		Username: user.Username,
+		Email:    user.Email,
+		Bio:      user.Bio,
+		Image:    user.Image,
+		Token:    bearerToken(c),
+	}})
 }
 
-func followProfile(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"message": "pong",
-	})
+// bearerToken returns the JWT from the request's "Authorization: Token
+// <jwt>" header, the same one JWTAuth/OptionalAuth already validated, so
+// responses that echo the user's token don't need to mint a new one.
+func bearerToken(c *gin.Context) string {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[1]
 }
 
-func unfollowProfile(c *gin.Context) {
-	c.JSON(200, gin.H{"token": authApi(c)})
+func (a *App) showProfile(c *gin.Context) {
 	username := c.Param("username")
-	var user User
-	getDB().First(&user, "username=?", username)
-	type Result struct {
-		Count uint
-	}
-	var result Result
-	getDB().Raw("SELECT COUNT(*) FROM followers WHERE user_id=? AND follower_id=?", user.ID).Scan(&result)
-	if result.Count == 0 {
-		c.JSON(402, gin.H{"error": "Unathorized"})
-		c.Abort()
-	}
-	getDB().Raw("DELETE FROM followers WHERE user_id=? AND follower_id=?", user.ID)
-	// c.JSON(200, gin.H{
-	// 	"profile": Profile{},
-	// })
-}
-
-func getTags(c *gin.Context) {
-	var tags []Tag
-	getDB().Find(&tags)
+	viewerID := currentViewerID(c)
+
+	profile, err := a.Profiles.Get(username, viewerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"profile not found"}}})
+		return
+	}
+	c.JSON(200, gin.H{"profile": profile})
+}
+
+// followProfile follows a profile identified either by a local username or
+// by a "username@domain" remote handle. Following a remote profile is
+// resolved via WebFinger and acknowledged with an outbound Follow activity.
+func (a *App) followProfile(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	profile, err := a.Profiles.Follow(user.ID, c.Param("username"), a.baseURL(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	c.JSON(200, gin.H{"profile": profile})
+}
+
+func (a *App) unfollowProfile(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	profile, err := a.Profiles.Unfollow(user.ID, c.Param("username"), a.baseURL(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+	c.JSON(200, gin.H{"profile": profile})
+}
+
+func (a *App) getTags(c *gin.Context) {
+	var tags []model.Tag
+	a.DB.Find(&tags)
 	c.JSON(200, gin.H{
 		"tags": tags,
 	})
 }
 
-func authApi(c *gin.Context) *jwt.Token {
-	tokenString := c.Request.Header.Get("Authorization")
-	type MyCustomClaims struct {
-		UserId uint64 `json:"user_id"`
-		jwt.StandardClaims
+func (a *App) baseURL(c *gin.Context) string {
+	if a.Config.AppURL != "" {
+		return strings.TrimRight(a.Config.AppURL, "/")
+	}
+	scheme := "https"
+	if c.Request.TLS == nil {
+		scheme = "http"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// webfingerHandler serves GET /.well-known/webfinger?resource=acct:user@host
+// so remote servers can discover a local user's actor document.
+func (a *App) webfingerHandler(c *gin.Context) {
+	resource := c.Query("resource")
+	username := strings.TrimPrefix(resource, "acct:")
+	if i := strings.Index(username, "@"); i >= 0 {
+		username = username[:i]
+	}
+
+	user, err := a.Users.FindByUsername(username)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"not found"}}})
+		return
+	}
+
+	c.JSON(http.StatusOK, federation.NewWebfingerResponse(a.baseURL(c), user.Username))
+}
+
+// actorHandler serves GET /users/:username, the ActivityPub actor document
+// for a local user.
+func (a *App) actorHandler(c *gin.Context) {
+	user, err := a.Users.FindByUsername(c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"not found"}}})
+		return
+	}
+
+	actor := federation.NewActor(a.baseURL(c), user.Username, user.Username, user.Bio, user.PublicKey)
+	c.Header("Content-Type", federation.ActivityContentType)
+	c.JSON(http.StatusOK, actor)
+}
+
+// outboxHandler serves GET /users/:username/outbox as a read-only
+// OrderedCollection of the user's articles, each wrapped in a Create
+// activity.
+func (a *App) outboxHandler(c *gin.Context) {
+	user, err := a.Users.FindByUsername(c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"not found"}}})
+		return
 	}
-	bearerToken := strings.Split(tokenString, " ")
 
-	token, err := jwt.ParseWithClaims(bearerToken[1], &MyCustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if jwt.GetSigningMethod("HS256") != token.Method {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	var articles []model.Article
+	a.DB.Where("user_id = ?", user.ID).Order("created_at desc").Find(&articles)
+
+	actorID := a.baseURL(c) + "/users/" + user.Username
+	items := make([]federation.Activity, 0, len(articles))
+	for _, article := range articles {
+		articleURL := a.baseURL(c) + "/articles/" + article.Slug
+		object := federation.ArticleObject{
+			ID:           articleURL,
+			Type:         "Article",
+			AttributedTo: actorID,
+			Name:         article.Title,
+			Summary:      article.Description,
+			Content:      article.Body,
+			Published:    article.CreatedAt.Format(time.RFC3339),
 		}
+		items = append(items, federation.NewCreateArticleActivity(articleURL+"#create", actorID, object))
+	}
 
-		return []byte(os.Getenv("JWT_SECRET")), nil
+	c.Header("Content-Type", federation.ActivityContentType)
+	c.JSON(http.StatusOK, gin.H{
+		"@context":     federation.ContextActivityStreams,
+		"id":           actorID + "/outbox",
+		"type":         "OrderedCollection",
+		"totalItems":   len(items),
+		"orderedItems": items,
 	})
+}
+
+// inboxHandler serves POST /users/:username/inbox. It verifies the HTTP
+// Signature on the incoming activity against the sender's published public
+// key before mutating any local Follower state.
+func (a *App) inboxHandler(c *gin.Context) {
+	user, err := a.Users.FindByUsername(c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"errors": gin.H{"body": []string{"not found"}}})
+		return
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"could not read body"}}})
+		return
+	}
+
+	var envelope struct {
+		Type   string      `json:"type"`
+		Actor  string      `json:"actor"`
+		Object interface{} `json:"object"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"invalid activity"}}})
+		return
+	}
+
+	remoteActor, err := federation.FetchActor(envelope.Actor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"body": []string{"could not resolve actor"}}})
+		return
+	}
+	if err := federation.VerifyRequest(c.Request, body, remoteActor.PublicKey.PublicKeyPem); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"errors": gin.H{"body": []string{err.Error()}}})
+		return
+	}
+
+	var cached model.RemoteActor
+	if a.DB.Where("actor_id = ?", remoteActor.ID).First(&cached).Error != nil {
+		cached = model.RemoteActor{
+			ActorID:   remoteActor.ID,
+			Username:  remoteActor.PreferredUsername,
+			Inbox:     remoteActor.Inbox,
+			PublicKey: remoteActor.PublicKey.PublicKeyPem,
+		}
+		a.DB.Create(&cached)
+	}
 
-	if token == nil || err == nil || !token.Valid {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"message": "not authorized",
-			"error":   err.Error(),
-		})
+	switch envelope.Type {
+	case "Follow":
+		a.DB.Create(&model.Follower{UserID: user.ID, RemoteActorID: cached.ID})
+		actorID := a.baseURL(c) + "/users/" + user.Username
+		accept := federation.NewAcceptActivity(actorID+"#accept-"+cached.ActorID, actorID, envelope.Object)
+		go func() {
+			if err := federation.Deliver(accept, cached.Inbox, actorID, user.PrivateKey); err != nil {
+				log.Printf("federation: delivering Accept to %s: %v", cached.Inbox, err)
+			}
+		}()
+	case "Undo":
+		a.DB.Where("user_id = ? AND remote_actor_id = ?", user.ID, cached.ID).Delete(&model.Follower{})
 	}
 
-	return token
+	c.JSON(http.StatusAccepted, gin.H{"message": "accepted"})
 }
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/sawirricardo/realworld-go/auth"
+	"github.com/sawirricardo/realworld-go/model"
+	"github.com/sawirricardo/realworld-go/service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const testSecret = "test-secret"
+
+func newTestUsers(t *testing.T) (*service.UserService, *model.User) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Follower{}, &model.RemoteActor{}); err != nil {
+		t.Fatalf("migrating test db: %v", err)
+	}
+
+	users := service.NewUserService(db)
+	user, err := users.Create("jake", "jake@jake.jake", "jakejake")
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	return users, user
+}
+
+func signToken(t *testing.T, userID uint64, sid string) string {
+	t.Helper()
+	claims := jwt.MapClaims{"user_id": userID, "exp": time.Now().Add(time.Hour).Unix()}
+	if sid != "" {
+		claims["sid"] = sid
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("signing test token: %v", err)
+	}
+	return token
+}
+
+func newTestContext(authHeader string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if authHeader != "" {
+		c.Request.Header.Set("Authorization", authHeader)
+	}
+	return c, w
+}
+
+func TestJWTAuthSetsCurrentUser(t *testing.T) {
+	users, user := newTestUsers(t)
+	c, w := newTestContext("Token " + signToken(t, user.ID, ""))
+
+	JWTAuth(testSecret, users, nil)(c)
+
+	if c.IsAborted() {
+		t.Fatalf("request was aborted with status %d", w.Code)
+	}
+	got, ok := CurrentUser(c)
+	if !ok || got.ID != user.ID {
+		t.Fatalf("CurrentUser() = %+v, %v, want user %d, true", got, ok, user.ID)
+	}
+}
+
+func TestJWTAuthRejectsMissingHeader(t *testing.T) {
+	users, _ := newTestUsers(t)
+	c, w := newTestContext("")
+
+	JWTAuth(testSecret, users, nil)(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if _, ok := CurrentUser(c); ok {
+		t.Fatal("expected no current user for a rejected request")
+	}
+}
+
+func TestJWTAuthRejectsRevokedSession(t *testing.T) {
+	users, user := newTestUsers(t)
+	sessions := auth.NewSessionCache(auth.NewMemory(), time.Hour)
+	sid, err := sessions.Put(user.ID, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if err := sessions.Revoke(sid); err != nil {
+		t.Fatalf("Revoke returned error: %v", err)
+	}
+
+	c, w := newTestContext("Token " + signToken(t, user.ID, sid))
+	JWTAuth(testSecret, users, sessions)(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestOptionalAuthAllowsAnonymousRequest(t *testing.T) {
+	users, _ := newTestUsers(t)
+	c, w := newTestContext("")
+
+	OptionalAuth(testSecret, users, nil)(c)
+
+	if c.IsAborted() {
+		t.Fatalf("request was aborted with status %d", w.Code)
+	}
+	if _, ok := CurrentUser(c); ok {
+		t.Fatal("expected no current user for an anonymous request")
+	}
+}
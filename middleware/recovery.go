@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery converts a panic anywhere in the handler chain into a
+// RealWorld-shaped 500 JSON error instead of Gin's default behavior
+// (dumping a stack trace and closing the connection). It replaces
+// gin.Default()'s built-in recovery middleware so the error body matches
+// every other handler's.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"errors": gin.H{"body": []string{fmt.Sprint(r)}}})
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,141 @@
+// Package migration applies versioned SQL files on top of GORM's
+// AutoMigrate, tracking what has already run in a schema_migrations
+// table. Files live under db/migrations and follow the golang-migrate
+// naming convention (NNNNNN_name.up.sql / NNNNNN_name.down.sql) so the
+// same directory can later be pointed at the golang-migrate CLI if this
+// project outgrows its own runner.
+package migration
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var upFileRe = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+type file struct {
+	version int64
+	name    string
+	path    string
+}
+
+// Runner applies pending *.up.sql files found in Dir against DB, in
+// ascending version order, recording each applied version in
+// schema_migrations so it is never run twice.
+type Runner struct {
+	DB  *gorm.DB
+	Dir string
+}
+
+func NewRunner(db *gorm.DB, dir string) *Runner {
+	return &Runner{DB: db, Dir: dir}
+}
+
+// Up applies every pending migration in Dir, in order, each inside its
+// own transaction.
+func (r *Runner) Up() error {
+	if err := r.ensureSchemaMigrationsTable(); err != nil {
+		return err
+	}
+
+	applied, err := r.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	pending, err := r.pendingFiles(applied)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range pending {
+		if err := r.apply(f); err != nil {
+			return fmt.Errorf("migration: applying %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) ensureSchemaMigrationsTable() error {
+	return r.DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`).Error
+}
+
+func (r *Runner) appliedVersions() (map[int64]bool, error) {
+	var versions []int64
+	if err := r.DB.Raw("SELECT version FROM schema_migrations").Scan(&versions).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+func (r *Runner) pendingFiles(applied map[int64]bool) ([]file, error) {
+	entries, err := ioutil.ReadDir(r.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []file
+	for _, entry := range entries {
+		match := upFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration: invalid version in %s: %w", entry.Name(), err)
+		}
+		if applied[version] {
+			continue
+		}
+		files = append(files, file{
+			version: version,
+			name:    strings.TrimSuffix(match[2], ".up"),
+			path:    filepath.Join(r.Dir, entry.Name()),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].version < files[j].version })
+	return files, nil
+}
+
+func (r *Runner) apply(f file) error {
+	sqlBytes, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(string(sqlBytes)) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", f.version, f.name).Error
+	})
+}
+
+func splitStatements(sqlText string) []string {
+	return strings.Split(sqlText, ";")
+}
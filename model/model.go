@@ -0,0 +1,84 @@
+// Package model holds the GORM-backed persistence types shared by the
+// service layer and the HTTP handlers. Request/response DTOs stay with
+// their handlers; this package is for what actually gets stored.
+package model
+
+import "time"
+
+type User struct {
+	ID              uint64    `json:"-"`
+	Username        string    `json:"username"`
+	Email           string    `json:"email"`
+	Password        string    `json:"-"`
+	Bio             string    `json:"bio"`
+	Image           string    `json:"image"`
+	PrivateKey      string    `json:"-" gorm:"type:text"` // PEM-encoded, used to sign outbound activities
+	PublicKey       string    `json:"-" gorm:"type:text"` // PEM-encoded, published on the actor document
+	EmailVerifiedAt time.Time `json:"-"`
+	CreatedAt       time.Time `json:"-"`
+	UpdatedAt       time.Time `json:"-"`
+}
+
+// Profile is the public-facing view of a User from another user's
+// perspective (adds the viewer-relative Following flag).
+type Profile struct {
+	Username  string `json:"username"`
+	Bio       string `json:"bio"`
+	Image     string `json:"image"`
+	Following bool   `json:"following"`
+}
+
+type Article struct {
+	ID          uint64    `json:"-"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	Description string    `json:"description"`
+	Slug        string    `json:"slug" gorm:"uniqueIndex"`
+	UserID      uint64    `json:"-"`
+	User        User      `json:"author"`
+	Tags        []Tag     `json:"tagList" gorm:"many2many:article_tag"`
+	Favoriters  []User    `json:"-" gorm:"many2many:favoriters"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type Comment struct {
+	ID        uint64    `json:"id"`
+	Body      string    `json:"body"`
+	UserID    uint64    `json:"-"`
+	User      User      `json:"-"`
+	ArticleID uint64    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Tag struct {
+	ID        uint64    `json:"-"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"-" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Follower records that FollowerID (a local user) or RemoteActorID (a
+// cached remote actor) follows UserID. Exactly one of FollowerID and
+// RemoteActorID is set.
+type Follower struct {
+	ID            uint64    `json:"-"`
+	UserID        uint64    `json:"-"`
+	FollowerID    uint64    `json:"-"`
+	RemoteActorID uint64    `json:"-"`
+	CreatedAt     time.Time `json:"-"`
+}
+
+// RemoteActor is a cached copy of a remote ActivityPub actor we have
+// exchanged Follow activities with, keyed by its actor ID URL.
+type RemoteActor struct {
+	ID        uint64    `json:"-"`
+	ActorID   string    `json:"-" gorm:"uniqueIndex;size:255"`
+	Username  string    `json:"-"`
+	Inbox     string    `json:"-"`
+	PublicKey string    `json:"-" gorm:"type:text"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
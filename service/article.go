@@ -0,0 +1,357 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	"github.com/sawirricardo/realworld-go/federation"
+	"github.com/sawirricardo/realworld-go/model"
+	"gorm.io/gorm"
+)
+
+// ErrForbidden is returned by the mutating article/comment methods when
+// the caller is not the resource's author.
+var ErrForbidden = errors.New("service: not the author")
+
+// maxSlugAttempts bounds how many times Create/Update retry picking a
+// slug after losing a race to a concurrent insert, so a pathological
+// storm of collisions can't spin forever.
+const maxSlugAttempts = 5
+
+type ArticleService struct {
+	db *gorm.DB
+}
+
+func NewArticleService(db *gorm.DB) *ArticleService {
+	return &ArticleService{db: db}
+}
+
+// ArticleFilter narrows ArticleService.List by the query params the
+// RealWorld spec defines for GET /articles. The zero value matches
+// everything.
+type ArticleFilter struct {
+	Tag       string
+	Author    string
+	Favorited string
+	Limit     int
+	Offset    int
+}
+
+// List returns articles matching filter, most recently created first,
+// with author, tags and favoriters preloaded so the handler can shape
+// favorited/favoritesCount/following without further queries per article.
+func (s *ArticleService) List(filter ArticleFilter) ([]model.Article, error) {
+	query := s.scoped(filter)
+
+	var articles []model.Article
+	err := query.Preload("User").Preload("Tags").Preload("Favoriters").
+		Order("articles.created_at desc").
+		Find(&articles).Error
+	return articles, err
+}
+
+func (s *ArticleService) scoped(filter ArticleFilter) *gorm.DB {
+	query := s.db.Model(&model.Article{})
+
+	if filter.Tag != "" {
+		query = query.Joins("JOIN article_tag ON article_tag.article_id = articles.id").
+			Joins("JOIN tags ON tags.id = article_tag.tag_id").
+			Where("tags.name = ?", filter.Tag)
+	}
+	if filter.Author != "" {
+		query = query.Joins("JOIN users article_authors ON article_authors.id = articles.user_id").
+			Where("article_authors.username = ?", filter.Author)
+	}
+	if filter.Favorited != "" {
+		query = query.Joins("JOIN favoriters ON favoriters.article_id = articles.id").
+			Joins("JOIN users favoriting_users ON favoriting_users.id = favoriters.user_id").
+			Where("favoriting_users.username = ?", filter.Favorited)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return query.Offset(filter.Offset)
+}
+
+// Feed returns articles authored by users viewerID follows, most
+// recently created first.
+func (s *ArticleService) Feed(viewerID uint64, limit, offset int) ([]model.Article, error) {
+	query := s.db.Model(&model.Article{}).
+		Joins("JOIN followers ON followers.user_id = articles.user_id AND followers.follower_id = ?", viewerID)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	query = query.Offset(offset)
+
+	var articles []model.Article
+	err := query.Preload("User").Preload("Tags").Preload("Favoriters").
+		Order("articles.created_at desc").
+		Find(&articles).Error
+	return articles, err
+}
+
+// Get loads a single article by slug with author, tags and favoriters
+// preloaded.
+func (s *ArticleService) Get(slug string) (*model.Article, error) {
+	var article model.Article
+	err := s.db.Preload("User").Preload("Tags").Preload("Favoriters").
+		Where("slug = ?", slug).First(&article).Error
+	if err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// Create persists a new article by authorID, upserting tagNames and
+// generating a unique slug from the title, then, best-effort, fans out a
+// Create{Article} activity to the author's remote followers. Tag
+// upsert and slug selection run inside a transaction, retrying on a
+// collision against the unique index backing articles.slug/tags.slug
+// so a concurrent Create racing for the same slug or tag can't
+// duplicate either.
+func (s *ArticleService) Create(authorID uint64, title, description, body string, tagNames []string, baseURL string) (*model.Article, error) {
+	var article model.Article
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := s.upsertTags(tx, tagNames)
+		if err != nil {
+			return err
+		}
+
+		for attempt := 0; ; attempt++ {
+			article = model.Article{
+				Title:       title,
+				Description: description,
+				Body:        body,
+				Slug:        s.uniqueSlug(tx, title, 0),
+				UserID:      authorID,
+				Tags:        tags,
+			}
+			err := tx.Create(&article).Error
+			if err == nil {
+				return nil
+			}
+			if isDuplicateKeyError(err) && attempt < maxSlugAttempts {
+				continue
+			}
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.Get(article.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.fanOutCreate(baseURL, *created)
+	return created, nil
+}
+
+// Update applies the non-empty fields of title/description/body to the
+// article identified by slug, regenerating its slug if the title
+// changed, and returns the refreshed article. It returns ErrForbidden if
+// authorID did not write the article.
+func (s *ArticleService) Update(articleSlug string, authorID uint64, title, description, body string) (*model.Article, error) {
+	article, err := s.Get(articleSlug)
+	if err != nil {
+		return nil, err
+	}
+	if article.UserID != authorID {
+		return nil, ErrForbidden
+	}
+
+	updates := map[string]interface{}{}
+	if description != "" {
+		updates["description"] = description
+	}
+	if body != "" {
+		updates["body"] = body
+	}
+	if title == "" && len(updates) == 0 {
+		return article, nil
+	}
+
+	slugValue := article.Slug
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		rowUpdates := updates
+		for attempt := 0; ; attempt++ {
+			if title != "" {
+				rowUpdates = make(map[string]interface{}, len(updates)+1)
+				for k, v := range updates {
+					rowUpdates[k] = v
+				}
+				rowUpdates["title"] = title
+				slugValue = s.uniqueSlug(tx, title, article.ID)
+				rowUpdates["slug"] = slugValue
+			}
+
+			err := tx.Model(&model.Article{}).Where("id = ?", article.ID).Updates(rowUpdates).Error
+			if err == nil {
+				return nil
+			}
+			if title != "" && isDuplicateKeyError(err) && attempt < maxSlugAttempts {
+				continue
+			}
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(slugValue)
+}
+
+// Delete removes the article identified by slug along with its
+// comments. It returns ErrForbidden if authorID did not write the
+// article.
+func (s *ArticleService) Delete(articleSlug string, authorID uint64) error {
+	article, err := s.Get(articleSlug)
+	if err != nil {
+		return err
+	}
+	if article.UserID != authorID {
+		return ErrForbidden
+	}
+
+	if err := s.db.Where("article_id = ?", article.ID).Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	return s.db.Select("Tags", "Favoriters").Delete(&model.Article{}, article.ID).Error
+}
+
+// Favorite adds userID to the article's favoriters.
+func (s *ArticleService) Favorite(articleSlug string, userID uint64) (*model.Article, error) {
+	article, err := s.Get(articleSlug)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(article).Association("Favoriters").Append(&model.User{ID: userID}); err != nil {
+		return nil, err
+	}
+	return s.Get(articleSlug)
+}
+
+// Unfavorite removes userID from the article's favoriters.
+func (s *ArticleService) Unfavorite(articleSlug string, userID uint64) (*model.Article, error) {
+	article, err := s.Get(articleSlug)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(article).Association("Favoriters").Delete(&model.User{ID: userID}); err != nil {
+		return nil, err
+	}
+	return s.Get(articleSlug)
+}
+
+// uniqueSlug derives a URL slug from title, appending "-2", "-3", ... on
+// collision. excludeID skips the article being updated when checking for
+// collisions against itself. This is a pre-check, not a guarantee: the
+// unique index on articles.slug is what actually stops a race, and
+// callers retry uniqueSlug on a duplicate-key error from the insert it
+// feeds into.
+func (s *ArticleService) uniqueSlug(db *gorm.DB, title string, excludeID uint64) string {
+	base := slug.Make(title)
+	candidate := base
+	for suffix := 2; ; suffix++ {
+		var count int64
+		query := db.Model(&model.Article{}).Where("slug = ?", candidate)
+		if excludeID != 0 {
+			query = query.Where("id <> ?", excludeID)
+		}
+		query.Count(&count)
+		if count == 0 {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", base, suffix)
+	}
+}
+
+// upsertTags finds or creates a Tag per name (keyed by its slugified
+// form) so the same tag is reused across articles instead of
+// duplicated. Run inside db's transaction; the unique index on
+// tags.slug is the actual backstop against two concurrent creates
+// racing past FirstOrCreate's own check.
+func (s *ArticleService) upsertTags(db *gorm.DB, names []string) ([]model.Tag, error) {
+	tags := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		tag, err := s.findOrCreateTag(db, name)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// findOrCreateTag upserts a single tag, retrying the lookup if
+// FirstOrCreate lost a race to a concurrent insert of the same slug.
+func (s *ArticleService) findOrCreateTag(db *gorm.DB, name string) (model.Tag, error) {
+	for attempt := 0; ; attempt++ {
+		var tag model.Tag
+		err := db.Where(model.Tag{Slug: slug.Make(name)}).
+			Attrs(model.Tag{Name: name}).
+			FirstOrCreate(&tag).Error
+		if err == nil {
+			return tag, nil
+		}
+		if isDuplicateKeyError(err) && attempt < maxSlugAttempts {
+			continue
+		}
+		return model.Tag{}, err
+	}
+}
+
+// isDuplicateKeyError reports whether err is a unique-constraint
+// violation from the MySQL or SQLite drivers this service runs
+// against, so slug/tag creation can retry instead of surfacing a raw
+// DB error when two requests race for the same slug.
+func isDuplicateKeyError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Duplicate entry") || strings.Contains(msg, "UNIQUE constraint")
+}
+
+// fanOutCreate delivers a Create{Article} activity to every remote
+// follower inbox of the article's author. Remote delivery is best-effort:
+// a single unreachable inbox does not fail article creation.
+func (s *ArticleService) fanOutCreate(baseURL string, article model.Article) {
+	if article.User.PublicKey == "" || article.User.PrivateKey == "" {
+		return
+	}
+
+	actorID := baseURL + "/users/" + article.User.Username
+	articleURL := baseURL + "/articles/" + article.Slug
+
+	object := federation.ArticleObject{
+		ID:           articleURL,
+		Type:         "Article",
+		AttributedTo: actorID,
+		Name:         article.Title,
+		Summary:      article.Description,
+		Content:      article.Body,
+		Published:    article.CreatedAt.Format(time.RFC3339),
+	}
+	activity := federation.NewCreateArticleActivity(articleURL+"#create", actorID, object)
+
+	var followers []model.Follower
+	s.db.Where("user_id = ? AND remote_actor_id <> 0", article.UserID).Find(&followers)
+	for _, f := range followers {
+		var remote model.RemoteActor
+		if s.db.First(&remote, f.RemoteActorID).Error != nil {
+			continue
+		}
+		if err := federation.Deliver(activity, remote.Inbox, actorID, article.User.PrivateKey); err != nil {
+			log.Printf("federation: delivering article %s to %s: %v", article.Slug, remote.Inbox, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/sawirricardo/realworld-go/model"
+	"gorm.io/gorm"
+)
+
+func newTestArticleDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&model.Article{}, &model.Comment{}, &model.Tag{}); err != nil {
+		t.Fatalf("migrating test db: %v", err)
+	}
+	return db
+}
+
+func TestArticleServiceCreateGeneratesUniqueSlug(t *testing.T) {
+	db := newTestArticleDB(t)
+	users := NewUserService(db)
+	articles := NewArticleService(db)
+
+	author, err := users.Create("jake", "jake@jake.jake", "jakejake")
+	if err != nil {
+		t.Fatalf("Create user returned error: %v", err)
+	}
+
+	first, err := articles.Create(author.ID, "How to train your dragon", "", "body", []string{"dragons", "training"}, "http://example.com")
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if first.Slug != "how-to-train-your-dragon" {
+		t.Fatalf("Slug = %q, want %q", first.Slug, "how-to-train-your-dragon")
+	}
+	if len(first.Tags) != 2 {
+		t.Fatalf("Tags = %+v, want 2 tags", first.Tags)
+	}
+
+	second, err := articles.Create(author.ID, "How to train your dragon", "", "another body", nil, "http://example.com")
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if second.Slug != "how-to-train-your-dragon-2" {
+		t.Fatalf("Slug = %q, want collision-suffixed slug", second.Slug)
+	}
+}
+
+func TestArticleServiceCreateReusesExistingTag(t *testing.T) {
+	db := newTestArticleDB(t)
+	users := NewUserService(db)
+	articles := NewArticleService(db)
+
+	author, _ := users.Create("jake", "jake@jake.jake", "jakejake")
+	if _, err := articles.Create(author.ID, "First", "", "body", []string{"dragons"}, "http://example.com"); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if _, err := articles.Create(author.ID, "Second", "", "body", []string{"dragons"}, "http://example.com"); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	var count int64
+	db.Model(&model.Tag{}).Where("name = ?", "dragons").Count(&count)
+	if count != 1 {
+		t.Fatalf("tag %q was duplicated, count = %d", "dragons", count)
+	}
+}
+
+func TestArticleServiceUpdateRejectsNonAuthor(t *testing.T) {
+	db := newTestArticleDB(t)
+	users := NewUserService(db)
+	articles := NewArticleService(db)
+
+	author, _ := users.Create("jake", "jake@jake.jake", "jakejake")
+	other, _ := users.Create("anne", "anne@anne.anne", "anneanne")
+	article, err := articles.Create(author.ID, "Title", "", "body", nil, "http://example.com")
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	if _, err := articles.Update(article.Slug, other.ID, "New title", "", ""); err != ErrForbidden {
+		t.Fatalf("Update() error = %v, want ErrForbidden", err)
+	}
+
+	updated, err := articles.Update(article.Slug, author.ID, "New title", "", "")
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if updated.Title != "New title" || updated.Slug != "new-title" {
+		t.Fatalf("unexpected article after update: %+v", updated)
+	}
+}
+
+func TestArticleServiceFavoriteAndUnfavorite(t *testing.T) {
+	db := newTestArticleDB(t)
+	users := NewUserService(db)
+	articles := NewArticleService(db)
+
+	author, _ := users.Create("jake", "jake@jake.jake", "jakejake")
+	fan, _ := users.Create("anne", "anne@anne.anne", "anneanne")
+	article, err := articles.Create(author.ID, "Title", "", "body", nil, "http://example.com")
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	favorited, err := articles.Favorite(article.Slug, fan.ID)
+	if err != nil {
+		t.Fatalf("Favorite returned error: %v", err)
+	}
+	if len(favorited.Favoriters) != 1 {
+		t.Fatalf("Favoriters = %+v, want 1 favoriter", favorited.Favoriters)
+	}
+
+	unfavorited, err := articles.Unfavorite(article.Slug, fan.ID)
+	if err != nil {
+		t.Fatalf("Unfavorite returned error: %v", err)
+	}
+	if len(unfavorited.Favoriters) != 0 {
+		t.Fatalf("Favoriters = %+v, want no favoriters", unfavorited.Favoriters)
+	}
+}
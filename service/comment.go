@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/sawirricardo/realworld-go/model"
+	"gorm.io/gorm"
+)
+
+type CommentService struct {
+	db *gorm.DB
+}
+
+func NewCommentService(db *gorm.DB) *CommentService {
+	return &CommentService{db: db}
+}
+
+// List returns every comment on articleID, oldest first, with authors
+// preloaded.
+func (s *CommentService) List(articleID uint64) ([]model.Comment, error) {
+	var comments []model.Comment
+	err := s.db.Preload("User").Where("article_id = ?", articleID).Order("created_at asc").Find(&comments).Error
+	return comments, err
+}
+
+// Create adds a comment to articleID by userID.
+func (s *CommentService) Create(articleID, userID uint64, body string) (*model.Comment, error) {
+	comment := model.Comment{ArticleID: articleID, UserID: userID, Body: body}
+	if err := s.db.Create(&comment).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("User").First(&comment, comment.ID).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// Delete removes a comment by id. It returns ErrForbidden if userID did
+// not write it.
+func (s *CommentService) Delete(id, userID uint64) error {
+	var comment model.Comment
+	if err := s.db.First(&comment, id).Error; err != nil {
+		return err
+	}
+	if comment.UserID != userID {
+		return ErrForbidden
+	}
+	return s.db.Delete(&comment).Error
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/sawirricardo/realworld-go/federation"
+	"github.com/sawirricardo/realworld-go/model"
+	"gorm.io/gorm"
+)
+
+type ProfileService struct {
+	db *gorm.DB
+}
+
+func NewProfileService(db *gorm.DB) *ProfileService {
+	return &ProfileService{db: db}
+}
+
+// Get returns the profile for username as seen by viewerID (0 for an
+// anonymous caller), populating Following accordingly.
+func (s *ProfileService) Get(username string, viewerID uint64) (*model.Profile, error) {
+	var user model.User
+	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	following := false
+	if viewerID != 0 {
+		var count int64
+		s.db.Model(&model.Follower{}).Where("user_id = ? AND follower_id = ?", user.ID, viewerID).Count(&count)
+		following = count > 0
+	}
+
+	return &model.Profile{Username: user.Username, Bio: user.Bio, Image: user.Image, Following: following}, nil
+}
+
+// FollowingSet reports, for each id in authorIDs, whether viewerID
+// follows them, batched into a single query so listing N articles/
+// comments doesn't cost N lookups. Returns an empty map for an
+// anonymous viewer (viewerID == 0).
+func (s *ProfileService) FollowingSet(viewerID uint64, authorIDs []uint64) (map[uint64]bool, error) {
+	following := make(map[uint64]bool, len(authorIDs))
+	if viewerID == 0 || len(authorIDs) == 0 {
+		return following, nil
+	}
+
+	var followedIDs []uint64
+	err := s.db.Model(&model.Follower{}).
+		Where("follower_id = ? AND user_id IN ?", viewerID, authorIDs).
+		Pluck("user_id", &followedIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range followedIDs {
+		following[id] = true
+	}
+	return following, nil
+}
+
+// Follow makes followerID follow target, which may be a local username or
+// a "username@domain" remote handle. Following a remote profile is
+// resolved via WebFinger and acknowledged with an outbound Follow
+// activity, signed as actorID using actorID's keypair.
+func (s *ProfileService) Follow(followerID uint64, target, baseURL string) (*model.Profile, error) {
+	if strings.Contains(target, "@") {
+		return s.followRemote(followerID, target, baseURL)
+	}
+
+	var user model.User
+	if err := s.db.Where("username = ?", target).First(&user).Error; err != nil {
+		return nil, err
+	}
+	s.db.Create(&model.Follower{UserID: user.ID, FollowerID: followerID})
+	return &model.Profile{Username: user.Username, Bio: user.Bio, Image: user.Image, Following: true}, nil
+}
+
+// Unfollow is the inverse of Follow.
+func (s *ProfileService) Unfollow(followerID uint64, target, baseURL string) (*model.Profile, error) {
+	if strings.Contains(target, "@") {
+		return s.unfollowRemote(followerID, target, baseURL)
+	}
+
+	var user model.User
+	if err := s.db.Where("username = ?", target).First(&user).Error; err != nil {
+		return nil, err
+	}
+	s.db.Where("user_id = ? AND follower_id = ?", user.ID, followerID).Delete(&model.Follower{})
+	return &model.Profile{Username: user.Username, Bio: user.Bio, Image: user.Image, Following: false}, nil
+}
+
+func (s *ProfileService) followRemote(followerID uint64, handle, baseURL string) (*model.Profile, error) {
+	remote, err := s.resolveRemoteActor(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	var follower model.User
+	if err := s.db.First(&follower, followerID).Error; err != nil {
+		return nil, err
+	}
+	s.db.Create(&model.Follower{FollowerID: followerID, RemoteActorID: remote.ID})
+
+	actorID := baseURL + "/users/" + follower.Username
+	follow := federation.NewFollowActivity(actorID+"#follow-"+remote.ActorID, actorID, remote.ActorID)
+	go func() {
+		if err := federation.Deliver(follow, remote.Inbox, actorID, follower.PrivateKey); err != nil {
+			log.Printf("federation: delivering Follow to %s: %v", remote.Inbox, err)
+		}
+	}()
+
+	return &model.Profile{Username: handle, Following: true}, nil
+}
+
+func (s *ProfileService) unfollowRemote(followerID uint64, handle, baseURL string) (*model.Profile, error) {
+	var remote model.RemoteActor
+	if s.db.Where("username = ?", handle).First(&remote).Error == nil {
+		s.db.Where("remote_actor_id = ? AND follower_id = ?", remote.ID, followerID).Delete(&model.Follower{})
+
+		var follower model.User
+		s.db.First(&follower, followerID)
+		actorID := baseURL + "/users/" + follower.Username
+		follow := federation.NewFollowActivity(actorID+"#follow-"+remote.ActorID, actorID, remote.ActorID)
+		undo := federation.NewUndoActivity(actorID+"#undo-"+remote.ActorID, actorID, follow)
+		go func() {
+			if err := federation.Deliver(undo, remote.Inbox, actorID, follower.PrivateKey); err != nil {
+				log.Printf("federation: delivering Undo to %s: %v", remote.Inbox, err)
+			}
+		}()
+	}
+	return &model.Profile{Username: handle, Following: false}, nil
+}
+
+// resolveRemoteActor looks up a "username@domain" handle via WebFinger,
+// fetches its actor document, and caches it as a RemoteActor.
+func (s *ProfileService) resolveRemoteActor(handle string) (model.RemoteActor, error) {
+	var cached model.RemoteActor
+	if s.db.Where("username = ?", handle).First(&cached).Error == nil {
+		return cached, nil
+	}
+
+	parts := strings.SplitN(handle, "@", 2)
+	if len(parts) != 2 {
+		return model.RemoteActor{}, fmt.Errorf("service: invalid handle %q", handle)
+	}
+	username, domain := parts[0], parts[1]
+
+	webfingerURL := fmt.Sprintf("https://%s/.well-known/webfinger?resource=acct:%s@%s", domain, username, domain)
+	if err := federation.ValidateOutboundURL(webfingerURL, domain); err != nil {
+		return model.RemoteActor{}, err
+	}
+	resp, err := federation.Get(webfingerURL)
+	if err != nil {
+		return model.RemoteActor{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return model.RemoteActor{}, err
+	}
+
+	var wf federation.WebfingerResponse
+	if err := json.Unmarshal(body, &wf); err != nil {
+		return model.RemoteActor{}, err
+	}
+
+	var actorURL string
+	for _, link := range wf.Links {
+		if link.Rel == "self" {
+			actorURL = link.Href
+			break
+		}
+	}
+	if actorURL == "" {
+		return model.RemoteActor{}, fmt.Errorf("service: no actor link for %q", handle)
+	}
+	// Pin the actor link (and, below, its inbox) to the domain the
+	// handle claims, so a malicious WebFinger response can't redirect
+	// the fetch/delivery to an arbitrary host.
+	if err := federation.ValidateOutboundURL(actorURL, domain); err != nil {
+		return model.RemoteActor{}, err
+	}
+
+	actor, err := federation.FetchActor(actorURL)
+	if err != nil {
+		return model.RemoteActor{}, err
+	}
+	if err := federation.ValidateOutboundURL(actor.Inbox, domain); err != nil {
+		return model.RemoteActor{}, err
+	}
+
+	remote := model.RemoteActor{
+		ActorID:   actor.ID,
+		Username:  handle,
+		Inbox:     actor.Inbox,
+		PublicKey: actor.PublicKey.PublicKeyPem,
+	}
+	s.db.Create(&remote)
+	return remote, nil
+}
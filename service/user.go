@@ -0,0 +1,123 @@
+// Package service holds the business logic behind the HTTP handlers:
+// validation, persistence and any third-party calls (e.g. federation
+// delivery). Handlers are responsible only for request binding, auth and
+// response shaping.
+package service
+
+import (
+	"errors"
+
+	"github.com/sawirricardo/realworld-go/federation"
+	"github.com/sawirricardo/realworld-go/model"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCredentials is returned by Authenticate when the email is
+// unknown or the password does not match.
+var ErrInvalidCredentials = errors.New("service: invalid email or password")
+
+type UserService struct {
+	db *gorm.DB
+}
+
+func NewUserService(db *gorm.DB) *UserService {
+	return &UserService{db: db}
+}
+
+// Create registers a new user, hashing their password and generating the
+// RSA keypair their ActivityPub actor document is published under.
+func (s *UserService) Create(username, email, password string) (*model.User, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, publicKey, err := federation.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	user := model.User{
+		Username:   username,
+		Email:      email,
+		Password:   string(hashed),
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}
+	if err := s.db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Authenticate looks up a user by email and verifies password, returning
+// ErrInvalidCredentials for either an unknown email or a bad password so
+// callers can't distinguish the two.
+func (s *UserService) Authenticate(email, password string) (*model.User, error) {
+	var user model.User
+	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+// Update applies the non-empty fields of username/email/password/bio/
+// image to the user identified by id and returns the refreshed user. A
+// non-empty password is re-hashed before being stored.
+func (s *UserService) Update(id uint64, username, email, password, bio, image string) (*model.User, error) {
+	updates := map[string]interface{}{}
+	if username != "" {
+		updates["username"] = username
+	}
+	if email != "" {
+		updates["email"] = email
+	}
+	if bio != "" {
+		updates["bio"] = bio
+	}
+	if image != "" {
+		updates["image"] = image
+	}
+	if password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		updates["password"] = string(hashed)
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(&model.User{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.FindByID(id)
+}
+
+func (s *UserService) FindByID(id uint64) (*model.User, error) {
+	var user model.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserService) FindByEmail(email string) (*model.User, error) {
+	var user model.User
+	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserService) FindByUsername(username string) (*model.User, error) {
+	var user model.User
+	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/sawirricardo/realworld-go/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("opening test db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Follower{}, &model.RemoteActor{}); err != nil {
+		t.Fatalf("migrating test db: %v", err)
+	}
+	return db
+}
+
+func TestUserServiceCreate(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUserService(db)
+
+	user, err := users.Create("jake", "jake@jake.jake", "jakejake")
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	if user.Username != "jake" || user.Email != "jake@jake.jake" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	if user.Password == "jakejake" {
+		t.Fatal("password was not hashed")
+	}
+	if user.PrivateKey == "" || user.PublicKey == "" {
+		t.Fatal("actor keypair was not generated")
+	}
+}
+
+func TestUserServiceAuthenticate(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUserService(db)
+
+	if _, err := users.Create("jake", "jake@jake.jake", "jakejake"); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{"correct credentials", "jake@jake.jake", "jakejake", nil},
+		{"wrong password", "jake@jake.jake", "wrong", ErrInvalidCredentials},
+		{"unknown email", "nope@jake.jake", "jakejake", ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := users.Authenticate(tt.email, tt.password)
+			if err != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}